@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/export"
+)
+
+// runExportUser writes a user's decisions, incoming likes and matches.
+func runExportUser(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("export-user", flag.ContinueOnError)
+	userID := fs.String("user", "", "user ID to export (required)")
+	format := fs.String("format", export.FormatJSONL, "output format: jsonl or csv")
+	out := fs.String("out", "-", "output file, or - for stdout")
+	pageSize := fs.Int("page-size", export.DefaultPageSize, "rows read per query")
+	archive := fs.Bool("archive", true, "also read archived decisions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *userID == "" {
+		return errors.New("-user is required")
+	}
+
+	f := os.Stdout
+	if *out != "-" {
+		var err error
+		if f, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+	buf := bufio.NewWriter(f)
+	w, err := export.NewWriter(buf, *format)
+	if err != nil {
+		return err
+	}
+
+	database, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	exporter := &export.Exporter{DB: database, PageSize: *pageSize, IncludeArchive: *archive}
+	n, err := exporter.Export(ctx, *userID, w)
+	if err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if f != os.Stdout {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	slog.Info("user data exported", "records", n)
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/importer"
+	"github.com/KEdore/explore/internal/service"
+	"github.com/KEdore/explore/internal/validation"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// runImport loads historical decisions from a CSV or JSON Lines file.
+func runImport(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "input file (required)")
+	format := fs.String("format", "", "csv or jsonl; defaults to the file extension")
+	batchSize := fs.Int("batch-size", importer.DefaultBatchSize, "rows per INSERT")
+	checkpoint := fs.String("checkpoint", "", "checkpoint file (default <file>.checkpoint)")
+	errorsFile := fs.String("errors", "", "rejected rows are written here (default <file>.errors)")
+	progressEvery := fs.Duration("progress", 10*time.Second, "how often to log progress")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+		if *format == "json" || *format == "ndjson" {
+			*format = importer.FormatJSONL
+		}
+	}
+	if *checkpoint == "" {
+		*checkpoint = *file + ".checkpoint"
+	}
+	if *errorsFile == "" {
+		*errorsFile = *file + ".errors"
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	reader, err := importer.NewReader(bufio.NewReaderSize(in, 1<<20), *format)
+	if err != nil {
+		return err
+	}
+	rejected, err := os.OpenFile(*errorsFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer rejected.Close()
+
+	userIDPattern, err := regexp.Compile(cfg.UserIDPattern)
+	if err != nil {
+		return fmt.Errorf("invalid USER_ID_PATTERN: %w", err)
+	}
+	validator := service.NewValidator(userIDPattern)
+
+	database, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var lastReport time.Time
+	im := &importer.Importer{
+		Store:      service.NewExploreServer(database),
+		BatchSize:  *batchSize,
+		Checkpoint: *checkpoint,
+		Errors:     rejected,
+		// Rows get the same checks as PutDecision requests.
+		Validate: func(r importer.Row) error {
+			return violationsError(validator.Violations(&pb.PutDecisionRequest{
+				ActorUserId:     r.Actor,
+				RecipientUserId: r.Recipient,
+				LikedRecipient:  r.Liked,
+			}))
+		},
+		Progress: func(s importer.Stats) {
+			if time.Since(lastReport) >= *progressEvery {
+				lastReport = time.Now()
+				logStats("import progress", s)
+			}
+		},
+	}
+	stats, err := im.Run(ctx, reader)
+	logStats("import finished", stats)
+	if err != nil {
+		return err
+	}
+	if stats.Failed > 0 {
+		slog.Warn("some rows were rejected", "errors_file", *errorsFile)
+	}
+	return nil
+}
+
+func logStats(msg string, s importer.Stats) {
+	slog.Info(msg, "read", s.Read, "imported", s.Imported, "skipped", s.Skipped, "failed", s.Failed)
+}
+
+// violationsError joins validation violations into one error, or returns nil.
+func violationsError(violations []validation.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		msgs[i] = v.Field + ": " + v.Description
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
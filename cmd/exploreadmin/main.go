@@ -0,0 +1,80 @@
+// Command exploreadmin runs maintenance tasks directly against the Explore
+// database. It reads the same DB_* environment variables as the server.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/db"
+	"github.com/KEdore/explore/internal/dbconfig"
+	"github.com/KEdore/explore/internal/logging"
+)
+
+// command is a subcommand; run receives the arguments after its name.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, cfg *config.Config, args []string) error
+}
+
+var commands = []command{
+	{"export-user", "export everything stored about a user as JSON Lines or CSV", runExportUser},
+	{"import", "load historical decisions from CSV or JSON Lines", runImport},
+	{"snapshot", "write a consistent, checksummed snapshot of the dataset", runSnapshot},
+	{"restore", "verify a snapshot and load it into an empty database", runRestore},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: exploreadmin <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", c.name, c.summary)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == os.Args[1] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(os.Stderr, logging.Options{Level: cfg.LogLevel, Format: cfg.LogFormat}); err != nil {
+		slog.Error("Failed to configure logging", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, cfg, os.Args[2:]); err != nil {
+		slog.Error(cmd.name+" failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// openDB connects with the server's settings and retry policy.
+func openDB(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	return db.Connect(ctx, dbconfig.Options(cfg), dbconfig.RetryPolicy(cfg))
+}
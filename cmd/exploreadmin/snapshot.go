@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/db"
+	"github.com/KEdore/explore/internal/snapshot"
+)
+
+// runSnapshot writes a consistent snapshot of the decisions dataset.
+func runSnapshot(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	dir := fs.String("dir", "", "output directory; must be empty or missing (required)")
+	chunkRows := fs.Int("chunk-rows", snapshot.DefaultChunkRows, "rows per chunk file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("-dir is required")
+	}
+
+	database, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	m, err := (&snapshot.Exporter{DB: database, ChunkRows: *chunkRows}).Export(ctx, *dir)
+	if err != nil {
+		return err
+	}
+	logManifest("snapshot written", m)
+	return nil
+}
+
+// runRestore verifies a snapshot and loads it into an empty database.
+func runRestore(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	dir := fs.String("dir", "", "snapshot directory (required)")
+	verifyOnly := fs.Bool("verify-only", false, "check checksums and row counts without loading")
+	migrate := fs.Bool("migrate", true, "apply migrations before loading")
+	batchRows := fs.Int("batch-rows", snapshot.DefaultRestoreBatchRows, "rows per INSERT")
+	resume := fs.Bool("resume", false, "continue a failed restore of the same snapshot")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("-dir is required")
+	}
+
+	if *verifyOnly {
+		m, err := snapshot.Verify(*dir)
+		if err != nil {
+			return err
+		}
+		logManifest("snapshot verified", m)
+		return nil
+	}
+
+	database, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	if *migrate {
+		if err := db.Migrate(ctx, database); err != nil {
+			return err
+		}
+	}
+
+	m, err := (&snapshot.Restorer{DB: database, BatchRows: *batchRows, Resume: *resume}).Restore(ctx, *dir)
+	if err != nil {
+		return err
+	}
+	logManifest("snapshot restored", m)
+	return nil
+}
+
+func logManifest(msg string, m *snapshot.Manifest) {
+	args := []any{"schema_version", m.SchemaVersion, "created_at", m.CreatedAt}
+	for _, t := range m.Tables {
+		args = append(args, t.Name, t.Rows)
+	}
+	slog.Info(msg, args...)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/KEdore/explore/proto"
+)
+
+func runPut(ctx context.Context, c pb.ExploreServiceClient, out *printer, args []string) error {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	actor := fs.String("actor", "", "deciding user (required)")
+	recipient := fs.String("recipient", "", "user decided on (required)")
+	pass := fs.Bool("pass", false, "record a pass instead of a like")
+	key := fs.String("idempotency-key", "", "replay-safe key for retries")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *actor == "" || *recipient == "" {
+		return errors.New("-actor and -recipient are required")
+	}
+
+	req := &pb.PutDecisionRequest{
+		ActorUserId:     *actor,
+		RecipientUserId: *recipient,
+		LikedRecipient:  !*pass,
+	}
+	if *key != "" {
+		req.IdempotencyKey = key
+	}
+	res, err := c.PutDecision(ctx, req)
+	if err != nil {
+		return err
+	}
+	return out.message(res, []string{"MUTUAL"}, [][]string{{yesNo(res.GetMutualLikes())}})
+}
+
+func runList(ctx context.Context, c pb.ExploreServiceClient, out *printer, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	user := fs.String("user", "", "recipient whose likers to list (required)")
+	onlyNew := fs.Bool("new", false, "use ListNewLikedYou, hiding users already liked back")
+	limit := fs.Int("limit", 0, "stop after this many likers; 0 lists all")
+	since := fs.String("since", "", "only likes made at or after this RFC 3339 time")
+	until := fs.String("until", "", "only likes made before this RFC 3339 time")
+	unseen := fs.Bool("unseen", false, "only likes the user has not marked seen")
+	undecided := fs.Bool("undecided", false, "only likers the user has neither liked nor passed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == "" {
+		return errors.New("-user is required")
+	}
+	req := &pb.ListLikedYouRequest{RecipientUserId: *user}
+	if *unseen {
+		req.UnseenOnly = unseen
+	}
+	if *undecided {
+		req.UndecidedOnly = undecided
+	}
+	var err error
+	if req.SinceUnixTimestamp, err = unixFlag("since", *since); err != nil {
+		return err
+	}
+	if req.UntilUnixTimestamp, err = unixFlag("until", *until); err != nil {
+		return err
+	}
+
+	list := c.ListLikedYou
+	if *onlyNew {
+		list = c.ListNewLikedYou
+	}
+	likers, err := listAll(ctx, list, req, *limit)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(likers))
+	for _, l := range likers {
+		rows = append(rows, []string{l.GetActorId(), timestamp(l.GetUnixTimestamp())})
+	}
+	return out.message(&pb.ListLikedYouResponse{Likers: likers}, []string{"ACTOR", "LIKED AT"}, rows)
+}
+
+func runCount(ctx context.Context, c pb.ExploreServiceClient, out *printer, args []string) error {
+	fs := flag.NewFlagSet("count", flag.ContinueOnError)
+	user := fs.String("user", "", "recipient whose likers to count (required)")
+	unseen := fs.Bool("unseen", false, "use CountUnseenLikedYou, counting unseen likes not liked back")
+	undecided := fs.Bool("undecided", false, "only count likers the user has neither liked nor passed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == "" {
+		return errors.New("-user is required")
+	}
+	req := &pb.CountLikedYouRequest{RecipientUserId: *user}
+	if *undecided {
+		req.UndecidedOnly = undecided
+	}
+
+	count := c.CountLikedYou
+	if *unseen {
+		count = c.CountUnseenLikedYou
+	}
+	res, err := count(ctx, req)
+	if err != nil {
+		return err
+	}
+	return out.message(res, []string{"COUNT"}, [][]string{{uitoa(res.GetCount())}})
+}
+
+func runSeen(ctx context.Context, c pb.ExploreServiceClient, out *printer, args []string) error {
+	fs := flag.NewFlagSet("seen", flag.ContinueOnError)
+	user := fs.String("user", "", "recipient whose likes to mark (required)")
+	until := fs.String("until", "", "mark likes made at or before this RFC 3339 time; defaults to now unless -actors is set")
+	actors := fs.String("actors", "", "comma-separated actors whose likes to mark")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == "" {
+		return errors.New("-user is required")
+	}
+	req := &pb.MarkLikesSeenRequest{RecipientUserId: *user}
+	var err error
+	if req.SeenUntilUnixTimestamp, err = unixFlag("until", *until); err != nil {
+		return err
+	}
+	if *actors != "" {
+		req.ActorUserIds = strings.Split(*actors, ",")
+	}
+
+	res, err := c.MarkLikesSeen(ctx, req)
+	if err != nil {
+		return err
+	}
+	return out.message(res, []string{"SEEN UNTIL"}, [][]string{{timestamp(res.GetSeenUntilUnixTimestamp())}})
+}
+
+// runMatches derives matches as likers missing from the "new" list, since
+// ListNewLikedYou drops exactly the likers the user liked back.
+func runMatches(ctx context.Context, c pb.ExploreServiceClient, out *printer, args []string) error {
+	fs := flag.NewFlagSet("matches", flag.ContinueOnError)
+	user := fs.String("user", "", "user whose matches to list (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == "" {
+		return errors.New("-user is required")
+	}
+
+	all, err := listAll(ctx, c.ListLikedYou, &pb.ListLikedYouRequest{RecipientUserId: *user}, 0)
+	if err != nil {
+		return err
+	}
+	unmatched, err := listAll(ctx, c.ListNewLikedYou, &pb.ListLikedYouRequest{RecipientUserId: *user}, 0)
+	if err != nil {
+		return err
+	}
+	skip := make(map[string]bool, len(unmatched))
+	for _, l := range unmatched {
+		skip[l.GetActorId()] = true
+	}
+
+	var matches []*pb.ListLikedYouResponse_Liker
+	var rows [][]string
+	for _, l := range all {
+		if !skip[l.GetActorId()] {
+			matches = append(matches, l)
+			rows = append(rows, []string{l.GetActorId(), timestamp(l.GetUnixTimestamp())})
+		}
+	}
+	return out.message(&pb.ListLikedYouResponse{Likers: matches}, []string{"MATCH", "LIKED AT"}, rows)
+}
+
+type listFunc func(ctx context.Context, in *pb.ListLikedYouRequest, opts ...grpc.CallOption) (*pb.ListLikedYouResponse, error)
+
+// listAll follows pagination tokens from req until the last page or limit
+// likers. The filters in req are sent with every page.
+func listAll(ctx context.Context, list listFunc, req *pb.ListLikedYouRequest, limit int) ([]*pb.ListLikedYouResponse_Liker, error) {
+	var likers []*pb.ListLikedYouResponse_Liker
+	for {
+		res, err := list(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		likers = append(likers, res.GetLikers()...)
+		if limit > 0 && len(likers) >= limit {
+			return likers[:limit], nil
+		}
+		next := res.GetNextPaginationToken()
+		if next == "" {
+			return likers, nil
+		}
+		req.PaginationToken = &next
+	}
+}
+
+// unixFlag parses an optional RFC 3339 flag value into Unix seconds.
+func unixFlag(name, value string) (*uint64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil || t.Unix() < 0 {
+		return nil, fmt.Errorf("-%s must be an RFC 3339 time after 1970, got %q", name, value)
+	}
+	unix := uint64(t.Unix())
+	return &unix, nil
+}
@@ -0,0 +1,108 @@
+// Command explorectl calls ExploreService from the command line.
+//
+//	explorectl [global flags] <command> [flags]
+//
+// Connection settings default to the EXPLORE_* environment variables.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/KEdore/explore/internal/client"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// command is a subcommand; run receives the arguments after its name.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, c pb.ExploreServiceClient, out *printer, args []string) error
+}
+
+var commands = []command{
+	{"put", "record a decision", runPut},
+	{"list", "list a user's likers, following every page", runList},
+	{"count", "count a user's likers", runCount},
+	{"seen", "mark a user's likes as seen", runSeen},
+	{"matches", "list users who like a user and are liked back", runMatches},
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	env := client.OptionsFromEnv()
+	global := flag.NewFlagSet("explorectl", flag.ContinueOnError)
+	addr := global.String("addr", env.Address, "server address ("+client.EnvAddress+")")
+	caFile := global.String("ca", env.CAFile, "CA bundle; enables TLS ("+client.EnvCAFile+")")
+	serverName := global.String("server-name", env.ServerName, "TLS server name override ("+client.EnvServerName+")")
+	certFile := global.String("cert", env.CertFile, "client certificate for mTLS ("+client.EnvCertFile+")")
+	keyFile := global.String("key", env.KeyFile, "client key for mTLS ("+client.EnvKeyFile+")")
+	token := global.String("token", env.Token, "bearer token ("+client.EnvToken+")")
+	output := global.String("o", formatTable, "output format: table or json")
+	timeout := global.Duration("timeout", 30*time.Second, "overall deadline")
+	global.Usage = func() {
+		fmt.Fprintln(global.Output(), "usage: explorectl [global flags] <command> [flags]")
+		fmt.Fprintln(global.Output(), "\ncommands:")
+		for _, c := range commands {
+			fmt.Fprintf(global.Output(), "  %-8s %s\n", c.name, c.summary)
+		}
+		fmt.Fprintln(global.Output(), "\nglobal flags:")
+		global.PrintDefaults()
+	}
+	if err := global.Parse(os.Args[1:]); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == global.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		global.Usage()
+		return 2
+	}
+	out, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	conn, err := client.Dial(client.Options{
+		Address:    *addr,
+		CAFile:     *caFile,
+		ServerName: *serverName,
+		CertFile:   *certFile,
+		KeyFile:    *keyFile,
+		Token:      *token,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	if err := cmd.run(ctx, pb.NewExploreServiceClient(conn), out, global.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer renders results as an aligned table or as the response message in
+// protojson, which scripts can parse.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	if format != formatTable && format != formatJSON {
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+	return &printer{w: w, format: format}, nil
+}
+
+// message prints msg as JSON, or header and rows as a table.
+func (p *printer) message(msg proto.Message, header []string, rows [][]string) error {
+	if p.format == formatJSON {
+		b, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func uitoa(n uint64) string { return strconv.FormatUint(n, 10) }
+
+// timestamp formats a Unix time, leaving unknown (zero) times blank.
+func timestamp(unix uint64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(int64(unix), 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,117 @@
+// Command loadgen drives ExploreService at a target rate, replaying recorded
+// traffic or generating synthetic swipes, and prints latency percentiles and
+// error rates per RPC.
+//
+// Connection settings default to the EXPLORE_* environment variables, as
+// for explorectl.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/KEdore/explore/internal/client"
+	"github.com/KEdore/explore/internal/loadgen"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	env := client.OptionsFromEnv()
+	addr := flag.String("addr", env.Address, "server address ("+client.EnvAddress+")")
+	caFile := flag.String("ca", env.CAFile, "CA bundle; enables TLS ("+client.EnvCAFile+")")
+	serverName := flag.String("server-name", env.ServerName, "TLS server name override ("+client.EnvServerName+")")
+	certFile := flag.String("cert", env.CertFile, "client certificate for mTLS ("+client.EnvCertFile+")")
+	keyFile := flag.String("key", env.KeyFile, "client key for mTLS ("+client.EnvKeyFile+")")
+	token := flag.String("token", env.Token, "bearer token ("+client.EnvToken+"); needs a service principal to act for many users")
+
+	replay := flag.String("replay", "", "recorded traffic file to replay; synthetic traffic when empty")
+	loop := flag.Bool("loop", false, "replay the recording repeatedly until -duration")
+	users := flag.Int("users", 10000, "synthetic user population")
+	skew := flag.Float64("skew", 1.1, "Zipf exponent for picking users; 0 is uniform")
+	likeRatio := flag.Float64("like-ratio", 0.3, "share of synthetic decisions that are likes")
+	mix := flag.String("mix", "70,10,10,10", "synthetic weights for PutDecision,ListLikedYou,ListNewLikedYou,CountLikedYou")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for synthetic traffic")
+
+	qps := flag.Float64("qps", 100, "target calls per second")
+	duration := flag.Duration("duration", time.Minute, "how long to run")
+	concurrency := flag.Int("concurrency", 32, "calls in flight at most")
+	timeout := flag.Duration("timeout", 5*time.Second, "per-call deadline")
+	flag.Parse()
+
+	var source loadgen.Source
+	if *replay != "" {
+		f, err := os.Open(*replay)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		calls, err := loadgen.ReadRecording(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read %s: %v\n", *replay, err)
+			return 1
+		}
+		source = loadgen.NewReplay(calls, *loop)
+	} else {
+		var m loadgen.Mix
+		if _, err := fmt.Sscanf(*mix, "%d,%d,%d,%d", &m.Put, &m.List, &m.ListNew, &m.Count); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -mix %q: %v\n", *mix, err)
+			return 2
+		}
+		s, err := loadgen.NewSynthetic(loadgen.SyntheticOptions{
+			Users:     *users,
+			Skew:      *skew,
+			LikeRatio: *likeRatio,
+			Mix:       m,
+			Seed:      *seed,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		source = s
+	}
+
+	conn, err := client.Dial(client.Options{
+		Address:    *addr,
+		CAFile:     *caFile,
+		ServerName: *serverName,
+		CertFile:   *certFile,
+		KeyFile:    *keyFile,
+		Token:      *token,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runner := &loadgen.Runner{
+		Invoker:     conn,
+		Source:      source,
+		QPS:         *qps,
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+	}
+	report, err := runner.Run(ctx)
+	if report != nil {
+		report.Write(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -2,19 +2,25 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/logging"
 	"github.com/KEdore/explore/internal/server"
 )
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(os.Stderr, logging.Options{Level: cfg.LogLevel, Format: cfg.LogFormat}); err != nil {
+		slog.Error("Failed to configure logging", "error", err)
+		os.Exit(1)
 	}
 
 	// Create a context that cancels on interrupt signals.
@@ -29,11 +35,12 @@ func main() {
 
 	stop, err := server.RunServer(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+		slog.Error("Failed to start server", "error", err)
+		os.Exit(1)
 	}
 
 	// Block until context is done.
 	<-ctx.Done()
 	stop()
-	log.Println("Server stopped gracefully.")
+	slog.Info("Server stopped gracefully.")
 }
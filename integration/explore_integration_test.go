@@ -1,24 +1,55 @@
+//go:build integration
 // +build integration
 
 package integration_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"log"
+	"os"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 
 	pb "github.com/KEdore/explore/proto"
 )
 
+// transportCredentials dials with TLS when EXPLORE_TLS_CA_FILE is set, adding a
+// client certificate from EXPLORE_TLS_CERT_FILE/EXPLORE_TLS_KEY_FILE for mTLS.
+// Without it the test connects in plaintext, as docker-compose runs by default.
+func transportCredentials(t *testing.T) grpc.DialOption {
+	caFile := os.Getenv("EXPLORE_TLS_CA_FILE")
+	if caFile == "" {
+		return grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		t.Fatalf("read CA file: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(pem)
+	cfg := &tls.Config{RootCAs: roots, ServerName: os.Getenv("EXPLORE_TLS_SERVER_NAME")}
+	if certFile := os.Getenv("EXPLORE_TLS_CERT_FILE"); certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, os.Getenv("EXPLORE_TLS_KEY_FILE"))
+		if err != nil {
+			t.Fatalf("load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg))
+}
+
 // waitForServer is a helper that waits until the gRPC service is available.
-func waitForServer(addr string, timeout time.Duration) error {
+func waitForServer(addr string, creds grpc.DialOption, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+		conn, err := grpc.DialContext(ctx, addr, creds, grpc.WithBlock())
 		cancel()
 		if err == nil {
 			conn.Close()
@@ -32,14 +63,15 @@ func waitForServer(addr string, timeout time.Duration) error {
 // TestCountLikedYou verifies that when no user has liked the given recipient, the count is zero.
 func TestCountLikedYou(t *testing.T) {
 	addr := "localhost:9090"
-	if err := waitForServer(addr, 10*time.Second); err != nil {
+	creds := transportCredentials(t)
+	if err := waitForServer(addr, creds, 10*time.Second); err != nil {
 		t.Fatalf("gRPC server not ready at %s: %v", addr, err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+	conn, err := grpc.DialContext(ctx, addr, creds, grpc.WithBlock())
 	if err != nil {
 		t.Fatalf("Failed to connect to gRPC server: %v", err)
 	}
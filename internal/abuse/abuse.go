@@ -0,0 +1,77 @@
+// Package abuse scores actors' decision patterns to spot spam and bot likers.
+package abuse
+
+// Signals that can contribute to a score.
+const (
+	ReasonLikeVelocity   = "like_velocity"
+	ReasonLikeRatio      = "like_ratio"
+	ReasonLowReciprocity = "low_reciprocity"
+)
+
+// Signal weights. Velocity alone is enough to flag; a high like ratio is
+// only suspicious when almost nobody likes the actor back.
+const (
+	velocityWeight    = 0.5
+	likeRatioWeight   = 0.25
+	reciprocityWeight = 0.25
+)
+
+// Activity is an actor's decision history.
+type Activity struct {
+	Likes       int64
+	Passes      int64
+	MutualLikes int64
+	// WindowLikes counts likes in the current one-hour window.
+	WindowLikes int64
+}
+
+// Policy holds the thresholds for each signal.
+type Policy struct {
+	// MaxLikesPerHour trips the velocity signal when exceeded.
+	MaxLikesPerHour int64
+	// MaxLikeRatio trips when likes/(likes+passes) is above it.
+	MaxLikeRatio float64
+	// MinReciprocity trips when mutual likes/likes is below it.
+	MinReciprocity float64
+	// MinDecisions is the history needed before ratio signals apply.
+	MinDecisions int64
+	// Threshold is the score at which an actor is flagged.
+	Threshold float64
+}
+
+// DefaultPolicy flags several hundred likes an hour, or near-universal
+// liking with almost no matches over a few hundred decisions.
+var DefaultPolicy = Policy{
+	MaxLikesPerHour: 500,
+	MaxLikeRatio:    0.95,
+	MinReciprocity:  0.01,
+	MinDecisions:    200,
+	Threshold:       0.5,
+}
+
+// Score rates a in [0, 1] and lists the signals that tripped.
+func (p Policy) Score(a Activity) (score float64, reasons []string) {
+	if p.MaxLikesPerHour > 0 && a.WindowLikes > p.MaxLikesPerHour {
+		score += velocityWeight
+		reasons = append(reasons, ReasonLikeVelocity)
+	}
+	if decisions := a.Likes + a.Passes; decisions >= p.MinDecisions && decisions > 0 {
+		if float64(a.Likes)/float64(decisions) > p.MaxLikeRatio {
+			score += likeRatioWeight
+			reasons = append(reasons, ReasonLikeRatio)
+		}
+	}
+	if a.Likes >= p.MinDecisions && a.Likes > 0 {
+		if float64(a.MutualLikes)/float64(a.Likes) < p.MinReciprocity {
+			score += reciprocityWeight
+			reasons = append(reasons, ReasonLowReciprocity)
+		}
+	}
+	return score, reasons
+}
+
+// Flagged reports whether a scores at or above the policy threshold.
+func (p Policy) Flagged(a Activity) (bool, float64, []string) {
+	score, reasons := p.Score(a)
+	return score > 0 && score >= p.Threshold, score, reasons
+}
@@ -0,0 +1,55 @@
+package abuse_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KEdore/explore/internal/abuse"
+)
+
+func TestPolicy_Flagged(t *testing.T) {
+	p := abuse.DefaultPolicy
+	tests := []struct {
+		name     string
+		activity abuse.Activity
+		flagged  bool
+		reasons  []string
+	}{
+		{
+			name:     "regular user",
+			activity: abuse.Activity{Likes: 300, Passes: 700, MutualLikes: 40, WindowLikes: 20},
+		},
+		{
+			name:     "new account below history threshold",
+			activity: abuse.Activity{Likes: 50, WindowLikes: 50},
+		},
+		{
+			name:     "velocity alone",
+			activity: abuse.Activity{Likes: 600, Passes: 600, MutualLikes: 60, WindowLikes: 501},
+			flagged:  true,
+			reasons:  []string{abuse.ReasonLikeVelocity},
+		},
+		{
+			name:     "generous but matched",
+			activity: abuse.Activity{Likes: 990, Passes: 10, MutualLikes: 100},
+			reasons:  []string{abuse.ReasonLikeRatio},
+		},
+		{
+			name:     "likes everyone, nobody likes back",
+			activity: abuse.Activity{Likes: 1000, MutualLikes: 2},
+			flagged:  true,
+			reasons:  []string{abuse.ReasonLikeRatio, abuse.ReasonLowReciprocity},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagged, _, reasons := p.Flagged(tt.activity)
+			if flagged != tt.flagged {
+				t.Errorf("expected flagged=%v, got %v", tt.flagged, flagged)
+			}
+			if !reflect.DeepEqual(reasons, tt.reasons) {
+				t.Errorf("expected reasons %v, got %v", tt.reasons, reasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package apperr is the service's error model. An *Error carries a gRPC code,
+// a machine-readable reason and a client-safe message; the underlying cause is
+// kept for logs but never sent to clients.
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/go-sql-driver/mysql"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain identifies this service in google.rpc.ErrorInfo.
+const Domain = "explore.kedore.github.com"
+
+// Reasons shared across RPCs.
+const (
+	ReasonInvalidArgument   = "INVALID_ARGUMENT"
+	ReasonInvalidPageToken  = "INVALID_PAGINATION_TOKEN"
+	ReasonNotFound          = "NOT_FOUND"
+	ReasonDatabaseTimeout   = "DATABASE_TIMEOUT"
+	ReasonDatabaseDown      = "DATABASE_UNAVAILABLE"
+	ReasonConflict          = "CONFLICT"
+	ReasonQuotaExceeded     = "QUOTA_EXCEEDED"
+	ReasonDeadlineExceeded  = "DEADLINE_EXCEEDED"
+	ReasonCanceled          = "CANCELED"
+	ReasonInternal          = "INTERNAL"
+	ReasonUnauthenticated   = "UNAUTHENTICATED"
+	ReasonPermissionDenied  = "PERMISSION_DENIED"
+	ReasonPreconditionError = "FAILED_PRECONDITION"
+)
+
+// Error is a classified service error.
+type Error struct {
+	Code     codes.Code
+	Reason   string
+	Message  string
+	Metadata map[string]string
+	// Details are additional google.rpc detail messages such as BadRequest.
+	Details []protoadapt.MessageV1
+	// Err is the cause. It appears in Error() for logging only.
+	Err error
+}
+
+// New creates an Error with a client-safe message.
+func New(code codes.Code, reason, message string) *Error {
+	return &Error{Code: code, Reason: reason, Message: message}
+}
+
+// Error includes the cause so server logs keep the full picture.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// GRPCStatus lets grpc-go send the error as a status with ErrorInfo attached.
+// Only Message, Reason, Metadata and Details reach the client.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code, e.Message)
+	details := append([]protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   e.Reason,
+		Domain:   Domain,
+		Metadata: e.Metadata,
+	}}, e.Details...)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// Wrap records cause on e and returns it.
+func (e *Error) Wrap(cause error) *Error {
+	e.Err = cause
+	return e
+}
+
+// With adds an ErrorInfo metadata entry.
+func (e *Error) With(key, value string) *Error {
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
+	}
+	e.Metadata[key] = value
+	return e
+}
+
+// WithDetails appends google.rpc detail messages.
+func (e *Error) WithDetails(details ...protoadapt.MessageV1) *Error {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
+// InvalidArgument reports a malformed request.
+func InvalidArgument(reason, message string) *Error {
+	return New(codes.InvalidArgument, reason, message)
+}
+
+// NotFound reports that the requested data does not exist.
+func NotFound(reason, message string) *Error {
+	return New(codes.NotFound, reason, message)
+}
+
+// ResourceExhausted reports a quota or rate limit breach.
+func ResourceExhausted(reason, message string) *Error {
+	return New(codes.ResourceExhausted, reason, message)
+}
+
+// Internal hides cause behind a generic message.
+func Internal(cause error) *Error {
+	return New(codes.Internal, ReasonInternal, "internal error").Wrap(cause)
+}
+
+// FromDB classifies a storage error. op describes what failed, e.g.
+// "put decision"; it is part of the client message, the driver text is not.
+func FromDB(err error, op string) *Error {
+	var ae *Error
+	if errors.As(err, &ae) {
+		return ae
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return NotFound(ReasonNotFound, op+": not found").Wrap(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return New(codes.DeadlineExceeded, ReasonDeadlineExceeded, op+": deadline exceeded").Wrap(err)
+	case errors.Is(err, context.Canceled):
+		return New(codes.Canceled, ReasonCanceled, op+": canceled").Wrap(err)
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn), errors.Is(err, sql.ErrConnDone):
+		return New(codes.Unavailable, ReasonDatabaseDown, op+": database unavailable").Wrap(err)
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case 1040, 1203: // too many connections
+			return New(codes.Unavailable, ReasonDatabaseDown, op+": database unavailable").Wrap(err)
+		case 1205, 3024: // lock wait timeout, max execution time exceeded
+			return New(codes.Unavailable, ReasonDatabaseTimeout, op+": database timed out").Wrap(err)
+		case 1213: // deadlock
+			return New(codes.Aborted, ReasonConflict, op+": conflicting concurrent update").Wrap(err)
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return New(codes.Unavailable, ReasonDatabaseTimeout, op+": database timed out").Wrap(err)
+		}
+		return New(codes.Unavailable, ReasonDatabaseDown, op+": database unavailable").Wrap(err)
+	}
+
+	return New(codes.Internal, ReasonInternal, op+": internal error").Wrap(err)
+}
@@ -0,0 +1,111 @@
+package apperr_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/apperr"
+)
+
+// TestFromDB verifies how storage errors map to gRPC codes.
+func TestFromDB(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"no rows", sql.ErrNoRows, codes.NotFound},
+		{"context deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"context canceled", context.Canceled, codes.Canceled},
+		{"bad connection", driver.ErrBadConn, codes.Unavailable},
+		{"too many connections", &mysql.MySQLError{Number: 1040, Message: "Too many connections"}, codes.Unavailable},
+		{"lock wait timeout", &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}, codes.Unavailable},
+		{"deadlock", &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, codes.Aborted},
+		{"syntax error", &mysql.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax near 'decisions'"}, codes.Internal},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := status.Convert(apperr.FromDB(tc.err, "failed to put decision"))
+			if st.Code() != tc.code {
+				t.Errorf("expected %v, got %v", tc.code, st.Code())
+			}
+			if strings.Contains(st.Message(), tc.err.Error()) {
+				t.Errorf("status message %q leaks the driver error", st.Message())
+			}
+		})
+	}
+}
+
+// TestGRPCStatus verifies that ErrorInfo and extra details are attached.
+func TestGRPCStatus(t *testing.T) {
+	err := apperr.InvalidArgument(apperr.ReasonInvalidPageToken, "invalid pagination token").
+		With("field", "pagination_token").
+		WithDetails(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "pagination_token", Description: "must be numeric"},
+		}}).
+		Wrap(errors.New(`strconv.Atoi: parsing "abc": invalid syntax`))
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || st.Message() != "invalid pagination token" {
+		t.Fatalf("unexpected status: %v", st)
+	}
+	var info *errdetails.ErrorInfo
+	var badRequest *errdetails.BadRequest
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			badRequest = d
+		}
+	}
+	if info == nil || info.Reason != apperr.ReasonInvalidPageToken || info.Domain != apperr.Domain || info.Metadata["field"] != "pagination_token" {
+		t.Errorf("unexpected ErrorInfo: %v", info)
+	}
+	if badRequest == nil || len(badRequest.FieldViolations) != 1 {
+		t.Errorf("expected BadRequest details, got %v", badRequest)
+	}
+	if !strings.Contains(err.Error(), "strconv.Atoi") {
+		t.Errorf("expected Error() to keep the cause for logging, got %q", err.Error())
+	}
+}
+
+// TestUnaryServerInterceptor verifies that unclassified and wrapped errors do not leak.
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := apperr.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/explore.ExploreService/PutDecision"}
+	cases := map[string]struct {
+		err  error
+		code codes.Code
+		msg  string
+	}{
+		"plain error": {errors.New("dial tcp 10.0.0.7:3306: connection refused"), codes.Internal, "internal error"},
+		"wrapped apperr": {
+			fmt.Errorf("outer: %w", apperr.NotFound(apperr.ReasonNotFound, "user not found").Wrap(errors.New("sql: no rows"))),
+			codes.NotFound, "user not found",
+		},
+		"status error": {status.Error(codes.PermissionDenied, "nope"), codes.PermissionDenied, "nope"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := intercept(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+				return nil, tc.err
+			})
+			st := status.Convert(err)
+			if st.Code() != tc.code || st.Message() != tc.msg {
+				t.Errorf("expected %v %q, got %v %q", tc.code, tc.msg, st.Code(), st.Message())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor makes sure no unclassified error reaches a client.
+// Errors without a gRPC status become Internal, and an *Error wrapped by
+// fmt.Errorf is unwrapped, because grpc-go would otherwise send the full
+// wrapped text as the status message. The original text stays available to
+// interceptors further out (such as logging) via Error().
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		var ae *Error
+		if errors.As(err, &ae) {
+			return resp, ae
+		}
+		if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+			return resp, err
+		}
+		return resp, Internal(err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package auth authenticates callers and checks that they act only on their
+// own behalf unless they are trusted service principals.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/KEdore/explore/internal/apperr"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request carries
+// none of the credentials it understands, so another one may be tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject is the caller's user ID, or the service name for service principals.
+	Subject string
+	// Service marks trusted backends that may act on behalf of any user.
+	Service bool
+	// Method names the mechanism that authenticated the caller, e.g. "jwt".
+	Method string
+}
+
+// Authenticator identifies the caller of an RPC.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Principal, error)
+}
+
+// Chain tries each authenticator in turn until one finds credentials.
+func Chain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+type chain []Authenticator
+
+func (c chain) Authenticate(ctx context.Context) (*Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+// WithPrincipal stores p in ctx.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the authenticated principal, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// OwnerFunc reports which user a request acts for and the field naming it.
+// ok is false for requests that are not scoped to a single user.
+type OwnerFunc func(req any) (userID, field string, ok bool)
+
+// bearerToken extracts the token from the authorization metadata.
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, v := range md.Get("authorization") {
+		if scheme, token, found := strings.Cut(v, " "); found && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token), true
+		}
+	}
+	return "", false
+}
+
+// UnaryServerInterceptor authenticates every RPC except those for which
+// exempt returns true (e.g. health checks), then checks that non-service
+// callers only act as themselves according to owner.
+func UnaryServerInterceptor(authn Authenticator, owner OwnerFunc, exempt func(fullMethod string) bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if exempt != nil && exempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		p, err := authn.Authenticate(ctx)
+		if err != nil {
+			msg := "invalid credentials"
+			if errors.Is(err, ErrNoCredentials) {
+				msg = "missing credentials"
+			}
+			return nil, apperr.New(codes.Unauthenticated, apperr.ReasonUnauthenticated, msg).Wrap(err)
+		}
+		if err := authorize(p, req, owner); err != nil {
+			return nil, err
+		}
+		return handler(WithPrincipal(ctx, p), req)
+	}
+}
+
+func authorize(p *Principal, req any, owner OwnerFunc) error {
+	if p.Service || owner == nil {
+		return nil
+	}
+	userID, field, ok := owner(req)
+	if !ok || userID == p.Subject {
+		return nil
+	}
+	return apperr.New(codes.PermissionDenied, apperr.ReasonPermissionDenied, field+" must match the authenticated user").
+		With("field", field)
+}
+
+// RequireService rejects callers that are not service principals on methods
+// for which restricted returns true. It must run after UnaryServerInterceptor.
+func RequireService(restricted func(fullMethod string) bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !restricted(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if p, ok := FromContext(ctx); !ok || !p.Service {
+			return nil, apperr.New(codes.PermissionDenied, apperr.ReasonPermissionDenied, "method is restricted to service principals")
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,221 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/auth"
+)
+
+var secret = []byte("test-secret")
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return s
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func claimsFor(sub string) jwt.MapClaims {
+	return jwt.MapClaims{"sub": sub, "iss": "issuer", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+// TestJWTAuthenticator_HS256 verifies signature, expiry, issuer and scope handling.
+func TestJWTAuthenticator_HS256(t *testing.T) {
+	a, err := auth.NewJWTAuthenticator(auth.JWTOptions{HS256Secret: secret, Issuer: "issuer", ServiceScope: "explore.service"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err := a.Authenticate(withToken(sign(t, jwt.SigningMethodHS256, secret, "", claimsFor("alice"))))
+	if err != nil || p.Subject != "alice" || p.Service {
+		t.Fatalf("expected user alice, got %+v, %v", p, err)
+	}
+
+	svc := claimsFor("matcher")
+	svc["scope"] = "read explore.service"
+	p, err = a.Authenticate(withToken(sign(t, jwt.SigningMethodHS256, secret, "", svc)))
+	if err != nil || !p.Service {
+		t.Fatalf("expected a service principal, got %+v, %v", p, err)
+	}
+
+	expired := claimsFor("alice")
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	wrongIssuer := claimsFor("alice")
+	wrongIssuer["iss"] = "someone-else"
+	for name, token := range map[string]string{
+		"wrong secret": sign(t, jwt.SigningMethodHS256, []byte("other"), "", claimsFor("alice")),
+		"expired":      sign(t, jwt.SigningMethodHS256, secret, "", expired),
+		"wrong issuer": sign(t, jwt.SigningMethodHS256, secret, "", wrongIssuer),
+		"garbage":      "not.a.jwt",
+	} {
+		if _, err := a.Authenticate(withToken(token)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := a.Authenticate(context.Background()); err != auth.ErrNoCredentials {
+		t.Errorf("expected ErrNoCredentials without a token, got %v", err)
+	}
+}
+
+// TestJWTAuthenticator_RS256 verifies tokens against a local JWKS file.
+func TestJWTAuthenticator_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+
+	a, err := auth.NewJWTAuthenticator(auth.JWTOptions{JWKSFile: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := a.Authenticate(withToken(sign(t, jwt.SigningMethodRS256, key, "k1", claimsFor("bob"))))
+	if err != nil || p.Subject != "bob" {
+		t.Fatalf("expected user bob, got %+v, %v", p, err)
+	}
+	if _, err := a.Authenticate(withToken(sign(t, jwt.SigningMethodRS256, key, "k2", claimsFor("bob")))); err == nil {
+		t.Error("expected an unknown key ID to be rejected")
+	}
+	// HS256 is not enabled, so an HMAC token must not be accepted with any key.
+	if _, err := a.Authenticate(withToken(sign(t, jwt.SigningMethodHS256, secret, "k1", claimsFor("bob")))); err == nil {
+		t.Error("expected HS256 to be rejected when only RS256 is configured")
+	}
+}
+
+type actorRequest struct{ actor string }
+
+func ownerOf(req any) (string, string, bool) {
+	if r, ok := req.(*actorRequest); ok {
+		return r.actor, "actor_user_id", true
+	}
+	return "", "", false
+}
+
+// TestUnaryServerInterceptor verifies authentication and ownership checks.
+func TestUnaryServerInterceptor(t *testing.T) {
+	a, err := auth.NewJWTAuthenticator(auth.JWTOptions{HS256Secret: secret, ServiceScope: "explore.service"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exempt := func(method string) bool { return method == "/grpc.health.v1.Health/Check" }
+	intercept := auth.UnaryServerInterceptor(a, ownerOf, exempt)
+
+	svc := claimsFor("matcher")
+	svc["scope"] = "explore.service"
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		req    any
+		code   codes.Code
+	}{
+		{"own request", withToken(sign(t, jwt.SigningMethodHS256, secret, "", claimsFor("alice"))), "/x/Put", &actorRequest{"alice"}, codes.OK},
+		{"someone else's request", withToken(sign(t, jwt.SigningMethodHS256, secret, "", claimsFor("alice"))), "/x/Put", &actorRequest{"bob"}, codes.PermissionDenied},
+		{"service principal", withToken(sign(t, jwt.SigningMethodHS256, secret, "", svc)), "/x/Put", &actorRequest{"bob"}, codes.OK},
+		{"missing token", context.Background(), "/x/Put", &actorRequest{"alice"}, codes.Unauthenticated},
+		{"exempt method", context.Background(), "/grpc.health.v1.Health/Check", nil, codes.OK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := intercept(tc.ctx, tc.req, &grpc.UnaryServerInfo{FullMethod: tc.method},
+				func(ctx context.Context, req any) (any, error) { return nil, nil })
+			if code := status.Code(err); code != tc.code {
+				t.Errorf("expected %v, got %v (%v)", tc.code, code, err)
+			}
+		})
+	}
+}
+
+// TestMTLSAuthenticator verifies that the verified client certificate identifies the caller.
+func TestMTLSAuthenticator(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://explore/matcher")
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "matcher"}, URIs: []*url.URL{spiffe}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+
+	a := auth.MTLSAuthenticator{ServiceIdentities: []string{"spiffe://explore/matcher"}}
+	p, err := a.Authenticate(ctx)
+	if err != nil || p.Subject != "spiffe://explore/matcher" || !p.Service {
+		t.Fatalf("expected a service principal from the URI SAN, got %+v, %v", p, err)
+	}
+
+	if _, err := a.Authenticate(context.Background()); err != auth.ErrNoCredentials {
+		t.Errorf("expected ErrNoCredentials without a peer, got %v", err)
+	}
+
+	// The chain falls through to the next authenticator when mTLS has nothing.
+	p, err = auth.Chain(a, auth.MTLSAuthenticator{}).Authenticate(ctx)
+	if err != nil || p.Method != "mtls" {
+		t.Errorf("expected the chain to authenticate, got %+v, %v", p, err)
+	}
+}
+
+// TestRequireService verifies that restricted methods need a service principal.
+func TestRequireService(t *testing.T) {
+	intercept := auth.RequireService(func(method string) bool { return method == "/x.Admin/Clear" })
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{"service principal", auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Service: true}), "/x.Admin/Clear", codes.OK},
+		{"user principal", auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice"}), "/x.Admin/Clear", codes.PermissionDenied},
+		{"no principal", context.Background(), "/x.Admin/Clear", codes.PermissionDenied},
+		{"unrestricted method", auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "alice"}), "/x/Put", codes.OK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := intercept(tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method},
+				func(ctx context.Context, req any) (any, error) { return nil, nil })
+			if code := status.Code(err); code != tc.code {
+				t.Errorf("expected %v, got %v (%v)", tc.code, code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTOptions configures JWT verification. At least one of HS256Secret and
+// JWKSFile must be set.
+type JWTOptions struct {
+	HS256Secret []byte
+	// JWKSFile is a local JSON Web Key Set with the RS256 public keys.
+	JWKSFile string
+	Issuer   string
+	Audience string
+	// ServiceScope marks tokens as service principals when present in the
+	// space-separated "scope" claim.
+	ServiceScope string
+}
+
+// JWTAuthenticator verifies bearer tokens signed with HS256 or RS256.
+type JWTAuthenticator struct {
+	opts    JWTOptions
+	rsaKeys map[string]*rsa.PublicKey
+	parser  *jwt.Parser
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope,omitempty"`
+}
+
+// NewJWTAuthenticator loads the keys described by opts.
+func NewJWTAuthenticator(opts JWTOptions) (*JWTAuthenticator, error) {
+	if len(opts.HS256Secret) == 0 && opts.JWKSFile == "" {
+		return nil, errors.New("jwt auth needs an HS256 secret or a JWKS file")
+	}
+	a := &JWTAuthenticator{opts: opts}
+
+	var methods []string
+	if len(opts.HS256Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if opts.JWKSFile != "" {
+		keys, err := LoadJWKS(opts.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.rsaKeys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	a.parser = jwt.NewParser(parserOpts...)
+	return a, nil
+}
+
+// Authenticate verifies the bearer token in the request metadata.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	raw, ok := bearerToken(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	var c claims
+	if _, err := a.parser.ParseWithClaims(raw, &c, a.key); err != nil {
+		return nil, err
+	}
+	if c.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	service := a.opts.ServiceScope != "" && slices.Contains(strings.Fields(c.Scope), a.opts.ServiceScope)
+	return &Principal{Subject: c.Subject, Service: service, Method: "jwt"}, nil
+}
+
+func (a *JWTAuthenticator) key(t *jwt.Token) (any, error) {
+	switch t.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.opts.HS256Secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := a.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(a.rsaKeys) == 1 {
+			for _, key := range a.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}
+}
+
+// LoadJWKS reads the RSA keys of a JSON Web Key Set, indexed by key ID.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: bad modulus: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: bad exponent: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS %s has no RSA signing keys", path)
+	}
+	return keys, nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"slices"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// MTLSAuthenticator identifies callers by their verified client certificate.
+type MTLSAuthenticator struct {
+	// ServiceIdentities lists the certificate identities trusted as service principals.
+	ServiceIdentities []string
+}
+
+// Authenticate returns the identity of the verified client certificate. The
+// first URI SAN (e.g. a SPIFFE ID) is preferred over the subject common name.
+func (a MTLSAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+	id := CertificateIdentity(info.State.VerifiedChains[0][0])
+	if id == "" {
+		return nil, errors.New("client certificate has no identity")
+	}
+	return &Principal{
+		Subject: id,
+		Service: slices.Contains(a.ServiceIdentities, id),
+		Method:  "mtls",
+	}, nil
+}
+
+// CertificateIdentity returns the first URI SAN of cert, or its common name.
+func CertificateIdentity(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return cert.Subject.CommonName
+}
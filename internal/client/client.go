@@ -0,0 +1,108 @@
+// Package client dials ExploreService for the command-line tools.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Options describes how to reach the server. Without CAFile the connection
+// is plaintext, as docker-compose runs by default.
+type Options struct {
+	Address    string
+	CAFile     string
+	ServerName string
+	// CertFile and KeyFile add a client certificate for mTLS.
+	CertFile string
+	KeyFile  string
+	// Token is sent as a bearer token on every call.
+	Token string
+}
+
+// Environment variables read by OptionsFromEnv.
+const (
+	EnvAddress    = "EXPLORE_ADDRESS"
+	EnvCAFile     = "EXPLORE_TLS_CA_FILE"
+	EnvServerName = "EXPLORE_TLS_SERVER_NAME"
+	EnvCertFile   = "EXPLORE_TLS_CERT_FILE"
+	EnvKeyFile    = "EXPLORE_TLS_KEY_FILE"
+	EnvToken      = "EXPLORE_TOKEN"
+)
+
+// DefaultAddress is used when EXPLORE_ADDRESS is unset.
+const DefaultAddress = "localhost:50051"
+
+// OptionsFromEnv reads the EXPLORE_* variables.
+func OptionsFromEnv() Options {
+	addr := os.Getenv(EnvAddress)
+	if addr == "" {
+		addr = DefaultAddress
+	}
+	return Options{
+		Address:    addr,
+		CAFile:     os.Getenv(EnvCAFile),
+		ServerName: os.Getenv(EnvServerName),
+		CertFile:   os.Getenv(EnvCertFile),
+		KeyFile:    os.Getenv(EnvKeyFile),
+		Token:      os.Getenv(EnvToken),
+	}
+}
+
+// TransportCredentials returns TLS credentials when CAFile is set and
+// plaintext otherwise.
+func (o Options) TransportCredentials() (credentials.TransportCredentials, error) {
+	if o.CAFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	pem, err := os.ReadFile(o.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+	}
+	cfg := &tls.Config{RootCAs: roots, ServerName: o.ServerName, MinVersion: tls.VersionTLS12}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// Dial creates a client connection; it does not wait for the server.
+func Dial(o Options, extra ...grpc.DialOption) (*grpc.ClientConn, error) {
+	creds, err := o.TransportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if o.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken{token: o.Token, secure: o.CAFile != ""}))
+	}
+	return grpc.NewClient(o.Address, append(opts, extra...)...)
+}
+
+// bearerToken attaches an authorization header to every call.
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+func (b bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + b.token}, nil
+}
+
+// RequireTransportSecurity lets tokens travel in plaintext only when TLS is
+// not configured at all, e.g. against a local docker-compose stack.
+func (b bearerToken) RequireTransportSecurity() bool { return b.secure }
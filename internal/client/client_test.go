@@ -0,0 +1,41 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/KEdore/explore/internal/client"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv(client.EnvAddress, "")
+	t.Setenv(client.EnvToken, "secret")
+	o := client.OptionsFromEnv()
+	if o.Address != client.DefaultAddress {
+		t.Errorf("expected default address, got %q", o.Address)
+	}
+	if o.Token != "secret" {
+		t.Errorf("expected token from env, got %q", o.Token)
+	}
+}
+
+func TestTransportCredentials(t *testing.T) {
+	creds, err := client.Options{}.TransportCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proto := creds.Info().SecurityProtocol; proto != "insecure" {
+		t.Errorf("expected plaintext without a CA file, got %q", proto)
+	}
+
+	if _, err := (client.Options{CAFile: "does-not-exist.pem"}).TransportCredentials(); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+}
+
+func TestDial(t *testing.T) {
+	conn, err := client.Dial(client.Options{Address: "localhost:0", Token: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn.Close()
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -11,6 +13,120 @@ type Config struct {
 	DBHost        string `envconfig:"DB_HOST" default:"localhost:3306"`
 	DBName        string `envconfig:"DB_NAME" required:"true"`
 	ServerAddress string `envconfig:"SERVER_ADDRESS" default:":50051"`
+
+	// Connection pool sizing.
+	DBMaxOpenConns    int           `envconfig:"DB_MAX_OPEN_CONNS" default:"25"`
+	DBMaxIdleConns    int           `envconfig:"DB_MAX_IDLE_CONNS" default:"25"`
+	DBConnMaxLifetime time.Duration `envconfig:"DB_CONN_MAX_LIFETIME" default:"5m"`
+	DBConnMaxIdleTime time.Duration `envconfig:"DB_CONN_MAX_IDLE_TIME" default:"1m"`
+	DBStatsInterval   time.Duration `envconfig:"DB_STATS_INTERVAL" default:"1m"`
+
+	// DSN options.
+	DBTLSMode           string            `envconfig:"DB_TLS_MODE" default:"false"`
+	DBTLSCAFile         string            `envconfig:"DB_TLS_CA_FILE"`
+	DBTLSCertFile       string            `envconfig:"DB_TLS_CERT_FILE"`
+	DBTLSKeyFile        string            `envconfig:"DB_TLS_KEY_FILE"`
+	DBTLSServerName     string            `envconfig:"DB_TLS_SERVER_NAME"`
+	DBCollation         string            `envconfig:"DB_COLLATION" default:"utf8mb4_unicode_ci"`
+	DBDialTimeout       time.Duration     `envconfig:"DB_DIAL_TIMEOUT" default:"5s"`
+	DBReadTimeout       time.Duration     `envconfig:"DB_READ_TIMEOUT" default:"30s"`
+	DBWriteTimeout      time.Duration     `envconfig:"DB_WRITE_TIMEOUT" default:"30s"`
+	DBInterpolateParams bool              `envconfig:"DB_INTERPOLATE_PARAMS" default:"false"`
+	DBParams            map[string]string `envconfig:"DB_PARAMS"`
+
+	// Startup connectivity and health reporting.
+	DBConnectTimeout        time.Duration `envconfig:"DB_CONNECT_TIMEOUT" default:"60s"`
+	DBConnectInitialBackoff time.Duration `envconfig:"DB_CONNECT_INITIAL_BACKOFF" default:"500ms"`
+	DBConnectMaxBackoff     time.Duration `envconfig:"DB_CONNECT_MAX_BACKOFF" default:"10s"`
+	DBMonitorInterval       time.Duration `envconfig:"DB_MONITOR_INTERVAL" default:"10s"`
+	HealthAddress           string        `envconfig:"HEALTH_ADDRESS" default:":8080"`
+	MetricsAddress          string        `envconfig:"METRICS_ADDRESS" default:":9100"`
+
+	// Tracing.
+	TracingExporter     string  `envconfig:"TRACING_EXPORTER" default:"none"`
+	TracingSampleRatio  float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"0.1"`
+	TracingServiceName  string  `envconfig:"TRACING_SERVICE_NAME" default:"explore"`
+	TracingOTLPEndpoint string  `envconfig:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool    `envconfig:"TRACING_OTLP_INSECURE" default:"false"`
+
+	// Logging. LogUserIDs is plain, hash or redact; hash needs LogUserIDSalt.
+	LogLevel      string `envconfig:"LOG_LEVEL" default:"info"`
+	LogFormat     string `envconfig:"LOG_FORMAT" default:"json"`
+	LogUserIDs    string `envconfig:"LOG_USER_IDS" default:"redact"`
+	LogUserIDSalt string `envconfig:"LOG_USER_ID_SALT"`
+
+	// Request validation.
+	UserIDPattern string `envconfig:"USER_ID_PATTERN" default:"^[A-Za-z0-9_.:@-]+$"`
+
+	// Authentication. AuthMode lists the enabled mechanisms (jwt, mtls), or none.
+	AuthMode           []string `envconfig:"AUTH_MODE" default:"none"`
+	AuthJWTHS256Secret string   `envconfig:"AUTH_JWT_HS256_SECRET"`
+	AuthJWTJWKSFile    string   `envconfig:"AUTH_JWT_JWKS_FILE"`
+	AuthJWTIssuer      string   `envconfig:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience    string   `envconfig:"AUTH_JWT_AUDIENCE"`
+	AuthServiceScope   string   `envconfig:"AUTH_SERVICE_SCOPE" default:"explore.service"`
+	// AuthMTLSServiceIdentities are client certificate identities trusted as service principals.
+	AuthMTLSServiceIdentities []string `envconfig:"AUTH_MTLS_SERVICE_IDENTITIES"`
+
+	// TLS for the gRPC listener. TLSClientAuth is none, request or require.
+	TLSCertFile       string        `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `envconfig:"TLS_KEY_FILE"`
+	TLSClientCAFile   string        `envconfig:"TLS_CLIENT_CA_FILE"`
+	TLSClientAuth     string        `envconfig:"TLS_CLIENT_AUTH" default:"none"`
+	TLSReloadInterval time.Duration `envconfig:"TLS_RELOAD_INTERVAL" default:"30s"`
+
+	// Rate limiting. Rates are requests per second; RateLimitRPC maps method
+	// names (e.g. PutDecision) to a per-instance rate.
+	RateLimitEnabled     bool               `envconfig:"RATE_LIMIT_ENABLED" default:"false"`
+	RateLimitRPC         map[string]float64 `envconfig:"RATE_LIMIT_RPC"`
+	RateLimitCallerRate  float64            `envconfig:"RATE_LIMIT_CALLER_RATE" default:"20"`
+	RateLimitCallerBurst int                `envconfig:"RATE_LIMIT_CALLER_BURST" default:"40"`
+	RateLimitActorRate   float64            `envconfig:"RATE_LIMIT_ACTOR_RATE" default:"1"`
+	RateLimitActorBurst  int                `envconfig:"RATE_LIMIT_ACTOR_BURST" default:"30"`
+
+	// Idempotency keys for PutDecision. A zero TTL disables replay.
+	IdempotencyTTL           time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+	IdempotencyPurgeInterval time.Duration `envconfig:"IDEMPOTENCY_PURGE_INTERVAL" default:"10m"`
+
+	// Abuse scoring. Flagged actors' likes are hidden from recipients.
+	AbuseDetectionEnabled bool    `envconfig:"ABUSE_DETECTION_ENABLED" default:"false"`
+	AbuseMaxLikesPerHour  int64   `envconfig:"ABUSE_MAX_LIKES_PER_HOUR" default:"500"`
+	AbuseMaxLikeRatio     float64 `envconfig:"ABUSE_MAX_LIKE_RATIO" default:"0.95"`
+	AbuseMinReciprocity   float64 `envconfig:"ABUSE_MIN_RECIPROCITY" default:"0.01"`
+	AbuseMinDecisions     int64   `envconfig:"ABUSE_MIN_DECISIONS" default:"200"`
+	AbuseFlagThreshold    float64 `envconfig:"ABUSE_FLAG_THRESHOLD" default:"0.5"`
+
+	// Decision expiry. A zero TTL keeps that kind of decision forever; expired
+	// decisions are hidden at once and deleted every DecisionExpiryInterval.
+	DecisionPassTTL        time.Duration `envconfig:"DECISION_PASS_TTL" default:"0s"`
+	DecisionLikeTTL        time.Duration `envconfig:"DECISION_LIKE_TTL" default:"0s"`
+	DecisionExpiryInterval time.Duration `envconfig:"DECISION_EXPIRY_INTERVAL" default:"1h"`
+
+	// Cold archival. Decisions unchanged for DecisionArchiveAfter move to
+	// decisions_archive every DecisionArchiveInterval; zero disables it. It
+	// must be at least DecisionLikeTTL, which must be set.
+	DecisionArchiveAfter    time.Duration `envconfig:"DECISION_ARCHIVE_AFTER" default:"0s"`
+	DecisionArchiveInterval time.Duration `envconfig:"DECISION_ARCHIVE_INTERVAL" default:"1h"`
+
+	// User erasure deletes at most this many rows per statement.
+	ErasureBatchSize int `envconfig:"ERASURE_BATCH_SIZE" default:"1000"`
+	// ErasureAuditKey keys the user ID hashes in the erasure audit trail.
+	ErasureAuditKey string `envconfig:"ERASURE_AUDIT_KEY"`
+
+	// Traffic recording for loadgen replay. User IDs are rewritten with
+	// RecordingUserIDs (plain or hash); RecordingRedactFields are cleared.
+	RecordingEnabled      bool     `envconfig:"RECORDING_ENABLED" default:"false"`
+	RecordingDir          string   `envconfig:"RECORDING_DIR" default:"recordings"`
+	RecordingSampleRate   float64  `envconfig:"RECORDING_SAMPLE_RATE" default:"0.01"`
+	RecordingMaxFileBytes int64    `envconfig:"RECORDING_MAX_FILE_BYTES" default:"67108864"`
+	RecordingMaxFiles     int      `envconfig:"RECORDING_MAX_FILES" default:"24"`
+	RecordingUserIDs      string   `envconfig:"RECORDING_USER_IDS" default:"hash"`
+	RecordingUserIDSalt   string   `envconfig:"RECORDING_USER_ID_SALT"`
+	RecordingRedactFields []string `envconfig:"RECORDING_REDACT_FIELDS" default:"idempotency_key"`
+
+	// Schema migrations and shutdown.
+	MigrateOnStart     bool          `envconfig:"MIGRATE_ON_START" default:"true"`
+	ShutdownDrainDelay time.Duration `envconfig:"SHUTDOWN_DRAIN_DELAY" default:"0s"`
 }
 
 // Load processes environment variables and returns a Config struct.
@@ -1,22 +1,159 @@
 package db
 
 import (
-    "database/sql"
-    "fmt"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
 
-    _ "github.com/go-sql-driver/mysql" // or your preferred driver
+	"github.com/go-sql-driver/mysql"
 )
 
-// NewMySQLClient opens a connection to the database.
-func NewMySQLClient(user, pass, host, dbname string) (*sql.DB, error) {
-    dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true", user, pass, host, dbname)
-    db, err := sql.Open("mysql", dsn)
-    if err != nil {
-        return nil, err
-    }
-    // ping the DB to ensure connectivity:
-    if err = db.Ping(); err != nil {
-        return nil, err
-    }
-    return db, nil
+// Options describes how to reach MySQL and how to size the connection pool.
+type Options struct {
+	User string
+	Pass string
+	Host string
+	Name string
+
+	// TLSMode is one of "false", "true", "skip-verify" or "preferred".
+	// When TLSCAFile or TLSCertFile is set, a custom TLS configuration is
+	// built from those files and TLSMode only controls skip-verify.
+	TLSMode       string
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+
+	Collation         string
+	DialTimeout       time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	InterpolateParams bool
+	// Params holds extra DSN parameters, e.g. session variables.
+	Params map[string]string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// MySQLConfig translates the options into a driver configuration.
+func (o Options) MySQLConfig() (*mysql.Config, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = o.User
+	cfg.Passwd = o.Pass
+	cfg.Net = "tcp"
+	cfg.Addr = o.Host
+	cfg.DBName = o.Name
+	cfg.ParseTime = true
+	cfg.Collation = o.Collation
+	cfg.Timeout = o.DialTimeout
+	cfg.ReadTimeout = o.ReadTimeout
+	cfg.WriteTimeout = o.WriteTimeout
+	cfg.InterpolateParams = o.InterpolateParams
+	if len(o.Params) > 0 {
+		cfg.Params = make(map[string]string, len(o.Params))
+		for k, v := range o.Params {
+			cfg.Params[k] = v
+		}
+	}
+
+	if o.TLSCAFile != "" || o.TLSCertFile != "" {
+		tlsCfg, err := o.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		cfg.TLS = tlsCfg
+	} else if o.TLSMode != "" {
+		cfg.TLSConfig = o.TLSMode
+	}
+	return cfg, nil
+}
+
+func (o Options) tlsConfig() (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName:         o.TLSServerName,
+		InsecureSkipVerify: o.TLSMode == "skip-verify",
+		MinVersion:         tls.VersionTLS12,
+	}
+	if o.TLSCAFile != "" {
+		pem, err := os.ReadFile(o.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read DB CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.TLSCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if o.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load DB client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+// Open creates a connection pool without checking connectivity.
+func Open(opts Options) (*sql.DB, error) {
+	cfg, err := opts.MySQLConfig()
+	if err != nil {
+		return nil, err
+	}
+	// NewConnector validates the TLS mode and collation/interpolation combination.
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	return db, nil
+}
+
+// NewMySQLClient opens a connection pool to the database and verifies connectivity.
+func NewMySQLClient(opts Options) (*sql.DB, error) {
+	db, err := Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	// ping the DB to ensure connectivity:
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// ReportStats logs the pool statistics every interval until ctx is done.
+func ReportStats(ctx context.Context, db *sql.DB, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s := db.Stats()
+			slog.Info("db pool stats", "event", "db.pool_stats",
+				"open", s.OpenConnections, "in_use", s.InUse, "idle", s.Idle,
+				"max_open", s.MaxOpenConnections, "wait_count", s.WaitCount, "wait_duration", s.WaitDuration,
+				"max_idle_closed", s.MaxIdleClosed, "max_idle_time_closed", s.MaxIdleTimeClosed,
+				"max_lifetime_closed", s.MaxLifetimeClosed)
+		}
+	}
 }
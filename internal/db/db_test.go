@@ -0,0 +1,61 @@
+package db_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KEdore/explore/internal/db"
+)
+
+// TestMySQLConfig verifies that the options end up in the DSN.
+func TestMySQLConfig(t *testing.T) {
+	opts := db.Options{
+		User:              "user",
+		Pass:              "pass",
+		Host:              "mysql:3306",
+		Name:              "explore",
+		TLSMode:           "skip-verify",
+		Collation:         "utf8mb4_unicode_ci",
+		DialTimeout:       5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		InterpolateParams: true,
+		Params:            map[string]string{"time_zone": "'+00:00'"},
+	}
+	cfg, err := opts.MySQLConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dsn := cfg.FormatDSN()
+	for _, want := range []string{
+		"user:pass@tcp(mysql:3306)/explore?",
+		"collation=utf8mb4_unicode_ci",
+		"interpolateParams=true",
+		"parseTime=true",
+		"readTimeout=30s",
+		"timeout=5s",
+		"tls=skip-verify",
+		"time_zone=%27%2B00%3A00%27",
+	} {
+		if !strings.Contains(dsn, want) {
+			t.Errorf("expected DSN %q to contain %q", dsn, want)
+		}
+	}
+}
+
+// TestNewMySQLClient_InvalidOptions verifies that bad option combinations fail before dialing.
+func TestNewMySQLClient_InvalidOptions(t *testing.T) {
+	cases := map[string]db.Options{
+		"unsafe collation with interpolation": {Host: "localhost:3306", Collation: "gbk_chinese_ci", InterpolateParams: true},
+		"unknown TLS mode":                    {Host: "localhost:3306", TLSMode: "bogus"},
+		"missing CA file":                     {Host: "localhost:3306", TLSCAFile: "/does/not/exist.pem"},
+	}
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := db.NewMySQLClient(opts); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package db_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/db"
+)
+
+// TestMigrations verifies that the embedded migrations are ordered and parsed.
+func TestMigrations(t *testing.T) {
+	migrations, err := db.Migrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("expected at least one migration")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("expected migration %d to have version %d, got %d (%s)", i, i+1, m.Version, m.Name)
+		}
+		if len(m.Statements) == 0 {
+			t.Errorf("migration %s has no statements", m.Name)
+		}
+	}
+}
+
+// TestPendingMigrations verifies that applied versions are skipped.
+func TestPendingMigrations(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	migrations, err := db.Migrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM schema_migrations`)).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+
+	pending, err := db.PendingMigrations(context.Background(), sqlDB)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pending) != len(migrations)-1 {
+		t.Errorf("expected %d pending migrations, got %d", len(migrations)-1, len(pending))
+	}
+	for _, m := range pending {
+		if m.Version == 1 {
+			t.Errorf("expected applied migration 1 to be skipped")
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -1,4 +1,4 @@
-CREATE TABLE decisions (
+CREATE TABLE IF NOT EXISTS decisions (
     actor_user_id VARCHAR(255) NOT NULL,
     recipient_user_id VARCHAR(255) NOT NULL,
     liked_recipient BOOLEAN NOT NULL,
@@ -0,0 +1,11 @@
+-- Stored PutDecision results, replayed when a client retries with the same key.
+-- A row with a NULL response is a request still in flight.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    actor_user_id VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    response VARBINARY(1024) NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (actor_user_id, idempotency_key),
+    INDEX idx_idempotency_expires_at (expires_at)
+);
@@ -0,0 +1,22 @@
+-- Per-actor decision counters feeding abuse scoring. window_likes counts likes
+-- since window_start and resets once the window is an hour old.
+CREATE TABLE IF NOT EXISTS actor_activity (
+    actor_user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    likes BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    passes BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    mutual_likes BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    window_start TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    window_likes BIGINT UNSIGNED NOT NULL DEFAULT 0
+);
+
+-- Flagged actors. Likes from actors with an uncleared flag are hidden from
+-- recipients; cleared rows are kept for review.
+CREATE TABLE IF NOT EXISTS abuse_flags (
+    actor_user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    score DOUBLE NOT NULL,
+    reasons VARCHAR(255) NOT NULL,
+    flagged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    cleared_at TIMESTAMP NULL,
+    cleared_by VARCHAR(255) NULL,
+    INDEX idx_abuse_flags_cleared_at (cleared_at, flagged_at)
+);
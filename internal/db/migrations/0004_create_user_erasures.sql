@@ -0,0 +1,15 @@
+-- Lookups and erasure by recipient would otherwise scan the whole table.
+CREATE INDEX idx_decisions_recipient ON decisions (recipient_user_id, liked_recipient);
+
+-- Audit trail of user erasures. The user ID itself is erased too; subject_hash
+-- is a keyed hash of it so a request can be matched to its record.
+CREATE TABLE IF NOT EXISTS user_erasures (
+    subject_hash CHAR(64) NOT NULL PRIMARY KEY,
+    requested_by VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP NULL,
+    attempts INT UNSIGNED NOT NULL DEFAULT 1,
+    decisions_made_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    decisions_received_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    derived_rows_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0
+);
@@ -0,0 +1,6 @@
+-- id gives decisions a stable insertion order for listing and keyset paging.
+-- Rows that predate this migration get its run time as their timestamps.
+ALTER TABLE decisions
+    ADD COLUMN id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE FIRST,
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
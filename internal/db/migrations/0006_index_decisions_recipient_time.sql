@@ -0,0 +1,4 @@
+-- Time-filtered liked-you lists range over updated_at within a recipient's
+-- likes. The new index covers every lookup the old one served.
+CREATE INDEX idx_decisions_recipient_time ON decisions (recipient_user_id, liked_recipient, updated_at);
+DROP INDEX idx_decisions_recipient ON decisions;
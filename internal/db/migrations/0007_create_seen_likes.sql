@@ -0,0 +1,16 @@
+-- Per-recipient seen watermark: likes made at or before seen_until are seen.
+CREATE TABLE IF NOT EXISTS like_watermarks (
+    recipient_user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    seen_until TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Likes marked seen individually, ahead of the watermark. A like made again
+-- after seen_at is unseen again. Rows the watermark has passed are deleted.
+CREATE TABLE IF NOT EXISTS seen_likes (
+    recipient_user_id VARCHAR(255) NOT NULL,
+    actor_user_id VARCHAR(255) NOT NULL,
+    seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (recipient_user_id, actor_user_id),
+    INDEX idx_seen_likes_actor (actor_user_id)
+);
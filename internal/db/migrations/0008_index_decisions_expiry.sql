@@ -0,0 +1,2 @@
+-- The expiry reaper deletes the oldest passes and likes in batches.
+CREATE INDEX idx_decisions_expiry ON decisions (liked_recipient, updated_at);
@@ -0,0 +1,18 @@
+-- Cold decisions, moved out of decisions once they have not changed for a
+-- while. A pair lives in exactly one of the two tables: PutDecision moves an
+-- archived pair back before updating it.
+CREATE TABLE IF NOT EXISTS decisions_archive (
+    id BIGINT UNSIGNED NOT NULL UNIQUE,
+    actor_user_id VARCHAR(255) NOT NULL,
+    recipient_user_id VARCHAR(255) NOT NULL,
+    liked_recipient BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (actor_user_id, recipient_user_id),
+    INDEX idx_decisions_archive_recipient (recipient_user_id, liked_recipient),
+    INDEX idx_decisions_archive_expiry (liked_recipient, updated_at)
+);
+
+-- The archiver moves the least recently changed decisions first.
+CREATE INDEX idx_decisions_updated_at ON decisions (updated_at);
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how Connect waits for the database at startup.
+type RetryPolicy struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Deadline bounds the total time spent retrying; zero means retry until ctx is done.
+	Deadline time.Duration
+}
+
+// Backoff returns the delay before the given retry attempt (starting at 1).
+// It grows exponentially up to MaxBackoff and applies full jitter.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(d)) + 1)
+}
+
+// Connect opens a connection pool and pings it until it succeeds, retrying
+// with backoff until the policy deadline or ctx expires.
+func Connect(ctx context.Context, opts Options, policy RetryPolicy) (*sql.DB, error) {
+	db, err := Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := WaitForDB(ctx, db, policy); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// WaitForDB pings db until it answers or the retry policy gives up.
+func WaitForDB(ctx context.Context, db *sql.DB, policy RetryPolicy) error {
+	if policy.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Deadline)
+		defer cancel()
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			slog.Info("database connected", "event", "db.connected", "attempts", attempt, "elapsed", time.Since(start))
+			return nil
+		}
+		wait := policy.Backoff(attempt)
+		slog.Warn("database not reachable, retrying", "event", "db.connect_retry", "attempt", attempt, "retry_in", wait, "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// Monitor pings db every interval and logs transitions between reachable and
+// unreachable. database/sql reconnects on its own; this makes the outages
+// visible and lets onChange track readiness. It returns when ctx is done.
+func Monitor(ctx context.Context, db *sql.DB, interval time.Duration, onChange func(up bool, err error)) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	up := true
+	var downSince time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err != nil && up:
+			up = false
+			downSince = time.Now()
+			slog.Error("database connection lost", "event", "db.disconnected", "error", err)
+			if onChange != nil {
+				onChange(false, err)
+			}
+		case err == nil && !up:
+			up = true
+			slog.Info("database connection restored", "event", "db.reconnected", "downtime", time.Since(downSince))
+			if onChange != nil {
+				onChange(true, nil)
+			}
+		}
+	}
+}
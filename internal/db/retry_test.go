@@ -0,0 +1,71 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/db"
+)
+
+// TestRetryPolicyBackoff verifies that backoff grows but never exceeds the cap.
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := db.RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 1; attempt <= 10; attempt++ {
+		limit := 100 * time.Millisecond << (attempt - 1)
+		if limit > time.Second {
+			limit = time.Second
+		}
+		for i := 0; i < 50; i++ {
+			if d := p.Backoff(attempt); d <= 0 || d > limit {
+				t.Fatalf("attempt %d: backoff %v outside (0, %v]", attempt, d, limit)
+			}
+		}
+	}
+}
+
+// TestWaitForDB_RecoversAfterFailures verifies that failed pings are retried.
+func TestWaitForDB_RecoversAfterFailures(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	mock.ExpectPing()
+
+	policy := db.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Deadline: time.Second}
+	if err := db.WaitForDB(context.Background(), sqlDB, policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestWaitForDB_GivesUpAtDeadline verifies that retries stop at the deadline.
+func TestWaitForDB_GivesUpAtDeadline(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	for i := 0; i < 100; i++ {
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	}
+
+	policy := db.RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, Deadline: 50 * time.Millisecond}
+	start := time.Now()
+	if err := db.WaitForDB(context.Background(), sqlDB, policy); err == nil {
+		t.Fatal("expected an error once the deadline passed")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to give up near the deadline, took %v", elapsed)
+	}
+}
@@ -0,0 +1,42 @@
+// Package dbconfig maps the environment configuration onto database client
+// settings, keeping the config package free of storage dependencies.
+package dbconfig
+
+import (
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/db"
+)
+
+// Options maps the DB_* settings onto the database client options.
+func Options(cfg *config.Config) db.Options {
+	return db.Options{
+		User:              cfg.DBUser,
+		Pass:              cfg.DBPass,
+		Host:              cfg.DBHost,
+		Name:              cfg.DBName,
+		TLSMode:           cfg.DBTLSMode,
+		TLSCAFile:         cfg.DBTLSCAFile,
+		TLSCertFile:       cfg.DBTLSCertFile,
+		TLSKeyFile:        cfg.DBTLSKeyFile,
+		TLSServerName:     cfg.DBTLSServerName,
+		Collation:         cfg.DBCollation,
+		DialTimeout:       cfg.DBDialTimeout,
+		ReadTimeout:       cfg.DBReadTimeout,
+		WriteTimeout:      cfg.DBWriteTimeout,
+		InterpolateParams: cfg.DBInterpolateParams,
+		Params:            cfg.DBParams,
+		MaxOpenConns:      cfg.DBMaxOpenConns,
+		MaxIdleConns:      cfg.DBMaxIdleConns,
+		ConnMaxLifetime:   cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime:   cfg.DBConnMaxIdleTime,
+	}
+}
+
+// RetryPolicy returns the DB_CONNECT_* policy for the initial connection.
+func RetryPolicy(cfg *config.Config) db.RetryPolicy {
+	return db.RetryPolicy{
+		InitialBackoff: cfg.DBConnectInitialBackoff,
+		MaxBackoff:     cfg.DBConnectMaxBackoff,
+		Deadline:       cfg.DBConnectTimeout,
+	}
+}
@@ -0,0 +1,327 @@
+// Package export writes everything stored about a user, for data-subject
+// access requests.
+package export
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Formats accepted by NewWriter.
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// Record kinds.
+const (
+	KindDecisionMade   = "decision_made"
+	KindLikeReceived   = "like_received"
+	KindMatch          = "match"
+	KindLikeSeen       = "like_seen"
+	KindLikesSeenUntil = "likes_seen_until"
+	KindActivity       = "activity"
+	KindAbuseFlag      = "abuse_flag"
+)
+
+// DefaultPageSize is how many rows each internal query reads.
+const DefaultPageSize = 1000
+
+// Record is one exported fact about the user.
+type Record struct {
+	Kind string `json:"kind"`
+	// OtherUserID is the recipient of a decision made, the actor of a like
+	// received, or the matched user.
+	OtherUserID string `json:"other_user_id"`
+	// Liked is set for decisions made; likes received, matches and seen likes
+	// are always likes.
+	Liked     bool      `json:"liked"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Details holds the fields of kinds that are not decisions: activity
+	// counters, an abuse flag's score and reasons, or a seen watermark.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Writer encodes records in one output format.
+type Writer interface {
+	Write(r Record) error
+	Flush() error
+}
+
+// NewWriter returns a Writer for format.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatJSONL, "":
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"kind", "other_user_id", "liked", "created_at", "updated_at", "details"}); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type jsonlWriter struct{ enc *json.Encoder }
+
+func (w *jsonlWriter) Write(r Record) error { return w.enc.Encode(r) }
+func (w *jsonlWriter) Flush() error         { return nil }
+
+type csvWriter struct{ w *csv.Writer }
+
+// Write puts Details in one column as a JSON object.
+func (w *csvWriter) Write(r Record) error {
+	details := ""
+	if len(r.Details) > 0 {
+		b, err := json.Marshal(r.Details)
+		if err != nil {
+			return err
+		}
+		details = string(b)
+	}
+	return w.w.Write([]string{
+		r.Kind, r.OtherUserID, strconv.FormatBool(r.Liked),
+		r.CreatedAt.UTC().Format(time.RFC3339), r.UpdatedAt.UTC().Format(time.RFC3339), details,
+	})
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// Exporter reads a user's data page by page so large histories are never
+// held in memory. IncludeArchive also reads decisions_archive.
+type Exporter struct {
+	DB             *sql.DB
+	PageSize       int
+	IncludeArchive bool
+}
+
+// query is one kind of record, paged by decisions.id. Each query selects
+// (id, other user, liked, created_at, updated_at) for rows with id > ?.
+type query struct {
+	kind string
+	sql  string
+}
+
+// queries returns the record queries over the given decision tables. A pair
+// lives in only one table, so a match may span both and each combination is
+// queried. Table names come from Export, never from input.
+func queries(tables []string) []query {
+	var qs []query
+	for _, t := range tables {
+		qs = append(qs, query{KindDecisionMade, `
+			SELECT id, recipient_user_id, liked_recipient, created_at, updated_at
+			FROM ` + t + `
+			WHERE actor_user_id = ? AND id > ?
+			ORDER BY id
+			LIMIT ?
+		`})
+	}
+	for _, t := range tables {
+		qs = append(qs, query{KindLikeReceived, `
+			SELECT id, actor_user_id, liked_recipient, created_at, updated_at
+			FROM ` + t + `
+			WHERE recipient_user_id = ? AND liked_recipient = TRUE AND id > ?
+			ORDER BY id
+			LIMIT ?
+		`})
+	}
+	// A match dates from the later of the two likes.
+	for _, t := range tables {
+		for _, rt := range tables {
+			qs = append(qs, query{KindMatch, `
+				SELECT d.id, d.recipient_user_id, TRUE,
+					GREATEST(d.created_at, r.created_at), GREATEST(d.updated_at, r.updated_at)
+				FROM ` + t + ` d
+				JOIN ` + rt + ` r
+					ON r.actor_user_id = d.recipient_user_id AND r.recipient_user_id = d.actor_user_id AND r.liked_recipient = TRUE
+				WHERE d.actor_user_id = ? AND d.liked_recipient = TRUE AND d.id > ?
+				ORDER BY d.id
+				LIMIT ?
+			`})
+		}
+	}
+	return qs
+}
+
+// Export writes every record about userID to w and reports how many were written.
+func (e *Exporter) Export(ctx context.Context, userID string, w Writer) (int, error) {
+	pageSize := e.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	tables := []string{"decisions"}
+	if e.IncludeArchive {
+		tables = append(tables, "decisions_archive")
+	}
+	total := 0
+	for _, q := range queries(tables) {
+		var after int64
+		for {
+			n, last, err := e.page(ctx, q, userID, after, pageSize, w)
+			total += n
+			if err != nil {
+				return total, fmt.Errorf("export %s: %w", q.kind, err)
+			}
+			if n < pageSize {
+				break
+			}
+			after = last
+		}
+	}
+	n, err := e.seenLikes(ctx, userID, pageSize, w)
+	total += n
+	if err != nil {
+		return total, fmt.Errorf("export %s: %w", KindLikeSeen, err)
+	}
+	n, err = e.state(ctx, userID, w)
+	total += n
+	if err != nil {
+		return total, err
+	}
+	return total, w.Flush()
+}
+
+// seenLikes writes the likes the user marked seen one by one, paged by actor.
+func (e *Exporter) seenLikes(ctx context.Context, userID string, limit int, w Writer) (int, error) {
+	total := 0
+	after := ""
+	for {
+		rows, err := e.DB.QueryContext(ctx, `
+			SELECT actor_user_id, seen_at FROM seen_likes
+			WHERE recipient_user_id = ? AND actor_user_id > ?
+			ORDER BY actor_user_id
+			LIMIT ?
+		`, userID, after, limit)
+		if err != nil {
+			return total, err
+		}
+		n := 0
+		for rows.Next() {
+			r := Record{Kind: KindLikeSeen, Liked: true}
+			if err := rows.Scan(&r.OtherUserID, &r.CreatedAt); err != nil {
+				rows.Close()
+				return total, err
+			}
+			r.UpdatedAt = r.CreatedAt
+			after = r.OtherUserID
+			if err := w.Write(r); err != nil {
+				rows.Close()
+				return total, err
+			}
+			n++
+		}
+		rows.Close()
+		total += n
+		if err := rows.Err(); err != nil || n < limit {
+			return total, err
+		}
+	}
+}
+
+// state writes the single-row facts kept about the user: their seen
+// watermark, activity counters and abuse flag. Missing rows are skipped.
+func (e *Exporter) state(ctx context.Context, userID string, w Writer) (int, error) {
+	var records []Record
+
+	var seenUntil, updatedAt time.Time
+	err := e.DB.QueryRowContext(ctx,
+		`SELECT seen_until, updated_at FROM like_watermarks WHERE recipient_user_id = ?`, userID,
+	).Scan(&seenUntil, &updatedAt)
+	switch {
+	case err == nil:
+		records = append(records, Record{
+			Kind: KindLikesSeenUntil, CreatedAt: updatedAt, UpdatedAt: updatedAt,
+			Details: map[string]string{"seen_until": seenUntil.UTC().Format(time.RFC3339)},
+		})
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("export %s: %w", KindLikesSeenUntil, err)
+	}
+
+	var likes, passes, mutual, windowLikes int64
+	var windowStart time.Time
+	err = e.DB.QueryRowContext(ctx, `
+		SELECT likes, passes, mutual_likes, window_likes, window_start
+		FROM actor_activity WHERE actor_user_id = ?
+	`, userID).Scan(&likes, &passes, &mutual, &windowLikes, &windowStart)
+	switch {
+	case err == nil:
+		records = append(records, Record{
+			Kind: KindActivity, CreatedAt: windowStart, UpdatedAt: windowStart,
+			Details: map[string]string{
+				"likes":        strconv.FormatInt(likes, 10),
+				"passes":       strconv.FormatInt(passes, 10),
+				"mutual_likes": strconv.FormatInt(mutual, 10),
+				"window_likes": strconv.FormatInt(windowLikes, 10),
+			},
+		})
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("export %s: %w", KindActivity, err)
+	}
+
+	var score float64
+	var reasons string
+	var flaggedAt time.Time
+	var clearedAt sql.NullTime
+	err = e.DB.QueryRowContext(ctx, `
+		SELECT score, reasons, flagged_at, cleared_at
+		FROM abuse_flags WHERE actor_user_id = ?
+	`, userID).Scan(&score, &reasons, &flaggedAt, &clearedAt)
+	switch {
+	case err == nil:
+		r := Record{
+			Kind: KindAbuseFlag, CreatedAt: flaggedAt, UpdatedAt: flaggedAt,
+			Details: map[string]string{
+				"score":   strconv.FormatFloat(score, 'g', -1, 64),
+				"reasons": reasons,
+				"cleared": strconv.FormatBool(clearedAt.Valid),
+			},
+		}
+		if clearedAt.Valid {
+			r.UpdatedAt = clearedAt.Time
+		}
+		records = append(records, r)
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("export %s: %w", KindAbuseFlag, err)
+	}
+
+	for i, r := range records {
+		if err := w.Write(r); err != nil {
+			return i, err
+		}
+	}
+	return len(records), nil
+}
+
+// page writes one page of q and returns the number of rows and the last id.
+func (e *Exporter) page(ctx context.Context, q query, userID string, after int64, limit int, w Writer) (int, int64, error) {
+	rows, err := e.DB.QueryContext(ctx, q.sql, userID, after, limit)
+	if err != nil {
+		return 0, after, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		r := Record{Kind: q.kind}
+		if err := rows.Scan(&after, &r.OtherUserID, &r.Liked, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return n, after, err
+		}
+		if err := w.Write(r); err != nil {
+			return n, after, err
+		}
+		n++
+	}
+	return n, after, rows.Err()
+}
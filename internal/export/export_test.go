@@ -0,0 +1,156 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/export"
+)
+
+func TestExporter_Export(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"id", "other", "liked", "created_at", "updated_at"}
+
+	// Decisions made fill the first page, so a second page is read after id 7.
+	mock.ExpectQuery(`FROM decisions\s+WHERE actor_user_id = \?`).
+		WithArgs("user1", int64(0), 2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(3, "a", true, ts, ts).AddRow(7, "b", false, ts, ts))
+	mock.ExpectQuery(`FROM decisions\s+WHERE actor_user_id = \?`).
+		WithArgs("user1", int64(7), 2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(9, "c", true, ts, ts))
+	mock.ExpectQuery(`WHERE recipient_user_id = \?`).
+		WithArgs("user1", int64(0), 2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(4, "a", true, ts, ts))
+	mock.ExpectQuery(`JOIN decisions r`).
+		WithArgs("user1", int64(0), 2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(3, "a", true, ts, ts))
+	mock.ExpectQuery(`FROM seen_likes`).
+		WithArgs("user1", "", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"actor_user_id", "seen_at"}).AddRow("a", ts))
+	mock.ExpectQuery(`FROM like_watermarks`).
+		WithArgs("user1").
+		WillReturnRows(sqlmock.NewRows([]string{"seen_until", "updated_at"}).AddRow(ts, ts))
+	mock.ExpectQuery(`FROM actor_activity`).
+		WithArgs("user1").
+		WillReturnRows(sqlmock.NewRows([]string{"likes", "passes", "mutual_likes", "window_likes", "window_start"}).
+			AddRow(2, 1, 1, 0, ts))
+	mock.ExpectQuery(`FROM abuse_flags`).
+		WithArgs("user1").
+		WillReturnRows(sqlmock.NewRows([]string{"score", "reasons", "flagged_at", "cleared_at"}).
+			AddRow(0.5, "like_velocity", ts, ts.Add(time.Hour)))
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n, err := (&export.Exporter{DB: db, PageSize: 2}).Export(context.Background(), "user1", w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 9 {
+		t.Errorf("expected 9 records, got %d", n)
+	}
+
+	want := strings.Join([]string{
+		"kind,other_user_id,liked,created_at,updated_at,details",
+		"decision_made,a,true,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,",
+		"decision_made,b,false,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,",
+		"decision_made,c,true,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,",
+		"like_received,a,true,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,",
+		"match,a,true,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,",
+		"like_seen,a,true,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,",
+		`likes_seen_until,,false,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,"{""seen_until"":""2024-05-01T12:00:00Z""}"`,
+		`activity,,false,2024-05-01T12:00:00Z,2024-05-01T12:00:00Z,"{""likes"":""2"",""mutual_likes"":""1"",""passes"":""1"",""window_likes"":""0""}"`,
+		`abuse_flag,,false,2024-05-01T12:00:00Z,2024-05-01T13:00:00Z,"{""cleared"":""true"",""reasons"":""like_velocity"",""score"":""0.5""}"`,
+	}, "\n") + "\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestExporter_IncludeArchive verifies archived decisions are read too, and
+// that matches are looked up across both tables.
+func TestExporter_IncludeArchive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cols := []string{"id", "other", "liked", "created_at", "updated_at"}
+	for _, q := range []struct {
+		sql      string
+		archived bool
+	}{
+		{`FROM decisions\s+WHERE actor_user_id`, false},
+		{`FROM decisions_archive\s+WHERE actor_user_id`, true},
+		{`FROM decisions\s+WHERE recipient_user_id`, false},
+		{`FROM decisions_archive\s+WHERE recipient_user_id`, false},
+		{`FROM decisions d\s+JOIN decisions r`, false},
+		{`FROM decisions d\s+JOIN decisions_archive r`, false},
+		{`FROM decisions_archive d\s+JOIN decisions r`, true},
+		{`FROM decisions_archive d\s+JOIN decisions_archive r`, true},
+	} {
+		rows := sqlmock.NewRows(cols)
+		if q.archived {
+			rows.AddRow(1, "old", true, ts, ts)
+		}
+		mock.ExpectQuery(q.sql).WithArgs("user1", int64(0), export.DefaultPageSize).WillReturnRows(rows)
+	}
+	mock.ExpectQuery(`FROM seen_likes`).WillReturnRows(sqlmock.NewRows([]string{"actor_user_id", "seen_at"}))
+	for _, table := range []string{"like_watermarks", "actor_activity", "abuse_flags"} {
+		mock.ExpectQuery(`FROM ` + table).WillReturnRows(sqlmock.NewRows([]string{"x"}))
+	}
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatJSONL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n, err := (&export.Exporter{DB: db, IncludeArchive: true}).Export(context.Background(), "user1", w)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 records, got %d", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestNewWriter_JSONL(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatJSONL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := w.Write(export.Record{Kind: export.KindMatch, OtherUserID: "a", Liked: true, CreatedAt: ts, UpdatedAt: ts}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"kind":"match","other_user_id":"a","liked":true,"created_at":"2024-05-01T12:00:00Z","updated_at":"2024-05-01T12:00:00Z"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("expected %s, got %s", want, buf.String())
+	}
+
+	if _, err := export.NewWriter(&buf, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,109 @@
+// Package health tracks whether the process is alive and ready to take traffic.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Status records readiness per named component. The process is ready only
+// when every registered component is ready.
+type Status struct {
+	mu         sync.RWMutex
+	components map[string]component
+	watchers   []func(ready bool)
+}
+
+type component struct {
+	ready  bool
+	reason string
+}
+
+// NewStatus returns a Status with the given components registered as not ready.
+func NewStatus(components ...string) *Status {
+	s := &Status{components: make(map[string]component, len(components))}
+	for _, name := range components {
+		s.components[name] = component{reason: "starting"}
+	}
+	return s
+}
+
+// Set updates the readiness of a component, registering it if needed.
+func (s *Status) Set(name string, ready bool, reason string) {
+	s.mu.Lock()
+	before := s.readyLocked()
+	s.components[name] = component{ready: ready, reason: reason}
+	after := s.readyLocked()
+	watchers := s.watchers
+	s.mu.Unlock()
+
+	if before != after {
+		for _, fn := range watchers {
+			fn(after)
+		}
+	}
+}
+
+// OnChange registers fn to be called whenever overall readiness flips.
+// fn is called once immediately with the current state.
+func (s *Status) OnChange(fn func(ready bool)) {
+	s.mu.Lock()
+	s.watchers = append(s.watchers, fn)
+	ready := s.readyLocked()
+	s.mu.Unlock()
+	fn(ready)
+}
+
+// Ready reports whether all components are ready.
+func (s *Status) Ready() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.readyLocked()
+}
+
+func (s *Status) readyLocked() bool {
+	for _, c := range s.components {
+		if !c.ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot returns the reason string of every component that is not ready.
+func (s *Status) Snapshot() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]string)
+	for name, c := range s.components {
+		if !c.ready {
+			out[name] = c.reason
+		}
+	}
+	return out
+}
+
+// Handler serves /healthz (liveness) and /readyz (readiness).
+// Liveness only says the process is serving HTTP; readiness reflects the components.
+func (s *Status) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		notReady := s.Snapshot()
+		w.Header().Set("Content-Type", "application/json")
+		if len(notReady) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"ready":     len(notReady) == 0,
+			"not_ready": notReady,
+		})
+	})
+	return mux
+}
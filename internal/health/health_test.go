@@ -0,0 +1,38 @@
+package health_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KEdore/explore/internal/health"
+)
+
+// TestStatusHandler verifies that readiness follows the components while liveness does not.
+func TestStatusHandler(t *testing.T) {
+	status := health.NewStatus("database")
+	h := status.Handler()
+
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	if code := get("/healthz"); code != http.StatusOK {
+		t.Errorf("expected liveness 200 while starting, got %d", code)
+	}
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness 503 while starting, got %d", code)
+	}
+
+	status.Set("database", true, "")
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Errorf("expected readiness 200 once ready, got %d", code)
+	}
+
+	status.Set("database", false, "ping failed")
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness 503 after losing the database, got %d", code)
+	}
+}
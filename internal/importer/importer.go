@@ -0,0 +1,194 @@
+// Package importer loads historical decisions from files in large,
+// idempotent batches that can resume from a checkpoint.
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/KEdore/explore/internal/service"
+)
+
+// DefaultBatchSize keeps each INSERT well below MySQL's placeholder limit.
+const DefaultBatchSize = 1000
+
+// Stats counts rows by outcome.
+type Stats struct {
+	Read     int
+	Imported int
+	// Skipped rows were committed by an earlier run, according to the checkpoint.
+	Skipped int
+	Failed  int
+}
+
+// Store writes batches of decisions. *service.ExploreServer implements it.
+type Store interface {
+	ImportDecisions(ctx context.Context, rows []service.ImportedDecision) error
+}
+
+// Importer writes rows to Store. Re-importing a row is harmless: the newer
+// of the stored and imported decisions wins.
+type Importer struct {
+	Store     Store
+	BatchSize int
+	// Validate rejects rows before they reach the database; it may be nil.
+	Validate func(Row) error
+	// Checkpoint is a file recording the last committed line. Rows at or
+	// before it are skipped, so an interrupted import resumes where it stopped.
+	Checkpoint string
+	// Errors receives one line per rejected row; it may be nil.
+	Errors io.Writer
+	// Progress is called after every committed batch; it may be nil.
+	Progress func(Stats)
+}
+
+// checkpoint is the JSON stored in the checkpoint file.
+type checkpoint struct {
+	Line      int       `json:"line"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Run imports every row from r.
+func (im *Importer) Run(ctx context.Context, r Reader) (Stats, error) {
+	var stats Stats
+	size := im.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if size > service.MaxImportBatch {
+		size = service.MaxImportBatch
+	}
+
+	resumeAfter, err := im.loadCheckpoint()
+	if err != nil {
+		return stats, err
+	}
+
+	batch := make([]Row, 0, size)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := im.insert(ctx, batch); err != nil {
+			return err
+		}
+		stats.Imported += len(batch)
+		if err := im.saveCheckpoint(batch[len(batch)-1].Line); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		if im.Progress != nil {
+			im.Progress(stats)
+		}
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		row, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			stats.Read++
+			if rowErr.Line > resumeAfter {
+				stats.Failed++
+				im.reject(rowErr)
+			} else {
+				stats.Skipped++
+			}
+			continue
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.Read++
+		if row.Line <= resumeAfter {
+			stats.Skipped++
+			continue
+		}
+		if im.Validate != nil {
+			if err := im.Validate(row); err != nil {
+				stats.Failed++
+				im.reject(&RowError{Line: row.Line, Err: err})
+				continue
+			}
+		}
+		batch = append(batch, row)
+		if len(batch) == size {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+		}
+	}
+	return stats, flush()
+}
+
+func (im *Importer) reject(err *RowError) {
+	if im.Errors != nil {
+		fmt.Fprintln(im.Errors, err.Error())
+	}
+}
+
+// insert writes a batch through the store.
+func (im *Importer) insert(ctx context.Context, rows []Row) error {
+	batch := make([]service.ImportedDecision, len(rows))
+	for i, r := range rows {
+		batch[i] = service.ImportedDecision{Actor: r.Actor, Recipient: r.Recipient, Liked: r.Liked, Time: r.Time}
+	}
+	if err := im.Store.ImportDecisions(ctx, batch); err != nil {
+		return fmt.Errorf("insert batch ending at line %d: %w", rows[len(rows)-1].Line, err)
+	}
+	return nil
+}
+
+func (im *Importer) loadCheckpoint() (int, error) {
+	if im.Checkpoint == "" {
+		return 0, nil
+	}
+	b, err := os.ReadFile(im.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return 0, fmt.Errorf("read checkpoint %s: %w", im.Checkpoint, err)
+	}
+	return cp.Line, nil
+}
+
+// saveCheckpoint replaces the checkpoint file atomically.
+func (im *Importer) saveCheckpoint(line int) error {
+	if im.Checkpoint == "" {
+		return nil
+	}
+	b, err := json.Marshal(checkpoint{Line: line, UpdatedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(im.Checkpoint), ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), im.Checkpoint)
+}
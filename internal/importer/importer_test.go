@@ -0,0 +1,182 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/importer"
+	"github.com/KEdore/explore/internal/service"
+)
+
+func readAll(t *testing.T, r importer.Reader) (rows []importer.Row, lines []int) {
+	t.Helper()
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows, lines
+		}
+		var rowErr *importer.RowError
+		if errors.As(err, &rowErr) {
+			lines = append(lines, rowErr.Line)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		rows     int
+		badLines []int
+	}{
+		{
+			name:   "csv with header",
+			format: importer.FormatCSV,
+			input: "actor_user_id,recipient_user_id,liked,timestamp\n" +
+				"a,b,true,2023-01-02T03:04:05Z\n" +
+				"a,c,maybe,2023-01-02T03:04:05Z\n" +
+				"b,a,false,1672628645\n",
+			rows:     2,
+			badLines: []int{3},
+		},
+		{
+			name:   "jsonl",
+			format: importer.FormatJSONL,
+			input: `{"actor_user_id":"a","recipient_user_id":"b","liked":true,"timestamp":"2023-01-02T03:04:05Z"}` + "\n" +
+				"\n" +
+				`{"actor_user_id":"a","recipient_user_id":"c"` + "\n" +
+				`{"actor_user_id":"b","recipient_user_id":"a","liked":false,"timestamp":1672628645}` + "\n",
+			rows:     2,
+			badLines: []int{3},
+		},
+		{
+			name:   "timestamps outside the TIMESTAMP range",
+			format: importer.FormatCSV,
+			input: "a,b,true,0\n" +
+				"a,c,true,-1\n" +
+				"a,d,true,2147483648\n" +
+				"a,e,true,2038-01-19T03:14:08Z\n" +
+				"a,f,true,2023-01-02T03:04:05Z\n",
+			rows:     1,
+			badLines: []int{1, 2, 3, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := importer.NewReader(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			rows, bad := readAll(t, r)
+			if len(rows) != tt.rows {
+				t.Fatalf("expected %d rows, got %d", tt.rows, len(rows))
+			}
+			if !slices.Equal(bad, tt.badLines) {
+				t.Errorf("expected bad lines %v, got %v", tt.badLines, bad)
+			}
+			for _, row := range rows {
+				if !row.Time.Equal(ts) {
+					t.Errorf("line %d: expected %v, got %v", row.Line, ts, row.Time)
+				}
+			}
+		})
+	}
+}
+
+func TestImporter_Run(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	input := "" +
+		"a,b,true,1672628645\n" + // line 1: committed by an earlier run
+		"a,c,true,1672628645\n" +
+		"a,a,true,1672628645\n" + // rejected by Validate
+		"b,a,false,1672628645\n" +
+		"c,a,true,1672628645\n"
+	checkpoint := filepath.Join(t.TempDir(), "import.checkpoint")
+	if err := os.WriteFile(checkpoint, []byte(`{"line":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Unix(1672628645, 0).UTC()
+	// The first batch moves an archived pair back before the upsert.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM decisions WHERE`).
+		WithArgs("a", "c", "b", "a").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM decisions_archive`).
+		WithArgs("a", "c", "b", "a").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO decisions \(id,`).
+		WithArgs("a", "c", "b", "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM decisions_archive`).
+		WithArgs("a", "c", "b", "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO decisions .* VALUES \(\?, \?, \?, \?, \?\), \(\?, \?, \?, \?, \?\)\s+ON DUPLICATE KEY UPDATE`).
+		WithArgs("a", "c", true, ts, ts, "b", "a", false, ts, ts).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	// The second batch is already hot, so the archive is not read.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM decisions WHERE`).
+		WithArgs("c", "a").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO decisions .* VALUES \(\?, \?, \?, \?, \?\)\s+ON DUPLICATE KEY UPDATE`).
+		WithArgs("c", "a", true, ts, ts).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var rejected bytes.Buffer
+	r, _ := importer.NewReader(strings.NewReader(input), importer.FormatCSV)
+	im := &importer.Importer{
+		Store:      service.NewExploreServer(db),
+		BatchSize:  2,
+		Checkpoint: checkpoint,
+		Errors:     &rejected,
+		Validate: func(r importer.Row) error {
+			if r.Actor == r.Recipient {
+				return errors.New("self decision")
+			}
+			return nil
+		},
+	}
+	stats, err := im.Run(context.Background(), r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := importer.Stats{Read: 5, Imported: 3, Skipped: 1, Failed: 1}
+	if stats != want {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+	if got := rejected.String(); got != "line 3: self decision\n" {
+		t.Errorf("unexpected rejected rows: %q", got)
+	}
+	b, err := os.ReadFile(checkpoint)
+	if err != nil || !strings.Contains(string(b), `"line":5`) {
+		t.Errorf("expected checkpoint at line 5, got %s (%v)", b, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
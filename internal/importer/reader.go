@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats accepted by NewReader.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Row is one historical decision.
+type Row struct {
+	// Line is the row's position in the input, used for checkpoints and error reports.
+	Line      int
+	Actor     string
+	Recipient string
+	Liked     bool
+	Time      time.Time
+}
+
+// RowError reports a row that could not be parsed or failed validation.
+// The import continues past it.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+func (e *RowError) Unwrap() error { return e.Err }
+
+// Reader yields rows until io.EOF. A *RowError skips one row; any other
+// error ends the import.
+type Reader interface {
+	Next() (Row, error)
+}
+
+// NewReader returns a Reader for format.
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.ReuseRecord = true
+		return &csvReader{r: cr}, nil
+	case FormatJSONL:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &jsonlReader{sc: sc}, nil
+	}
+	return nil, fmt.Errorf("unknown import format %q", format)
+}
+
+// csvReader reads actor_user_id,recipient_user_id,liked,timestamp rows. A
+// first row starting with actor_user_id is treated as a header.
+type csvReader struct {
+	r    *csv.Reader
+	read int
+}
+
+func (c *csvReader) Next() (Row, error) {
+	for {
+		rec, err := c.r.Read()
+		if err == io.EOF {
+			return Row{}, io.EOF
+		}
+		c.read++
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return Row{}, &RowError{Line: parseErr.StartLine, Err: err}
+			}
+			return Row{}, err
+		}
+		line, _ := c.r.FieldPos(0)
+		if c.read == 1 && len(rec) > 0 && strings.EqualFold(strings.TrimSpace(rec[0]), "actor_user_id") {
+			continue
+		}
+		if len(rec) != 4 {
+			return Row{}, &RowError{Line: line, Err: fmt.Errorf("expected 4 fields, got %d", len(rec))}
+		}
+		row, err := parseRow(line, rec[0], rec[1], rec[2], rec[3])
+		if err != nil {
+			return Row{}, &RowError{Line: line, Err: err}
+		}
+		return row, nil
+	}
+}
+
+// jsonlReader reads one object per line with actor_user_id,
+// recipient_user_id, liked and timestamp. Blank lines are skipped.
+type jsonlReader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+type jsonRow struct {
+	Actor     string          `json:"actor_user_id"`
+	Recipient string          `json:"recipient_user_id"`
+	Liked     *bool           `json:"liked"`
+	Timestamp json.RawMessage `json:"timestamp"`
+}
+
+func (j *jsonlReader) Next() (Row, error) {
+	for j.sc.Scan() {
+		j.line++
+		b := j.sc.Bytes()
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+		var jr jsonRow
+		if err := json.Unmarshal(b, &jr); err != nil {
+			return Row{}, &RowError{Line: j.line, Err: err}
+		}
+		if jr.Liked == nil {
+			return Row{}, &RowError{Line: j.line, Err: errors.New("liked is required")}
+		}
+		ts := strings.Trim(string(jr.Timestamp), `"`)
+		row, err := parseRow(j.line, jr.Actor, jr.Recipient, strconv.FormatBool(*jr.Liked), ts)
+		if err != nil {
+			return Row{}, &RowError{Line: j.line, Err: err}
+		}
+		return row, nil
+	}
+	if err := j.sc.Err(); err != nil {
+		return Row{}, err
+	}
+	return Row{}, io.EOF
+}
+
+func parseRow(line int, actor, recipient, liked, ts string) (Row, error) {
+	l, err := strconv.ParseBool(strings.TrimSpace(liked))
+	if err != nil {
+		return Row{}, fmt.Errorf("invalid liked value %q", liked)
+	}
+	t, err := parseTime(strings.TrimSpace(ts))
+	if err != nil {
+		return Row{}, err
+	}
+	return Row{
+		Line:      line,
+		Actor:     strings.TrimSpace(actor),
+		Recipient: strings.TrimSpace(recipient),
+		Liked:     l,
+		Time:      t,
+	}, nil
+}
+
+// The range of a MySQL TIMESTAMP column.
+var (
+	minTimestamp = time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC)
+	maxTimestamp = time.Date(2038, 1, 19, 3, 14, 7, 0, time.UTC)
+)
+
+// parseTime accepts RFC 3339 or Unix seconds within the TIMESTAMP range.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("timestamp is required")
+	}
+	var t time.Time
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		t = time.Unix(secs, 0)
+	} else if t, err = time.Parse(time.RFC3339, s); err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	t = t.UTC()
+	if t.Before(minTimestamp) || t.After(maxTimestamp) {
+		return time.Time{}, fmt.Errorf("timestamp %q is outside %s to %s",
+			s, minTimestamp.Format(time.RFC3339), maxTimestamp.Format(time.RFC3339))
+	}
+	return t, nil
+}
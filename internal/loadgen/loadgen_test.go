@@ -0,0 +1,222 @@
+package loadgen_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/loadgen"
+	pb "github.com/KEdore/explore/proto"
+)
+
+func TestReadRecording(t *testing.T) {
+	input := `{"method":"/explore.ExploreService/PutDecision","request":{"actorUserId":"a","recipientUserId":"b","likedRecipient":true},"code":"OK"}
+{"method":"/grpc.health.v1.Health/Check","request":{}}
+
+{"method":"/explore.ExploreService/CountLikedYou","request":{"recipientUserId":"b"}}
+{"method":"/explore.ExploreService/MarkLikesSeen","request":{"recipientUserId":"b","actorUserIds":["a"]}}
+{"method":"/explore.ExploreService/CountUnseenLikedYou","request":{"recipientUserId":"b"}}
+`
+	calls, err := loadgen.ReadRecording(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls, got %d", len(calls))
+	}
+	put, ok := calls[0].Request.(*pb.PutDecisionRequest)
+	if !ok || put.GetActorUserId() != "a" || !put.GetLikedRecipient() {
+		t.Errorf("unexpected first call: %v", calls[0])
+	}
+
+	seen, ok := calls[2].Request.(*pb.MarkLikesSeenRequest)
+	if !ok || len(seen.GetActorUserIds()) != 1 {
+		t.Errorf("unexpected third call: %v", calls[2])
+	}
+
+	replay := loadgen.NewReplay(calls, false)
+	for i := 0; i < 4; i++ {
+		if _, err := replay.Next(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := replay.Next(); err == nil {
+		t.Error("expected io.EOF after the last call")
+	}
+}
+
+func TestSynthetic(t *testing.T) {
+	s, err := loadgen.NewSynthetic(loadgen.SyntheticOptions{
+		Users:     100,
+		Skew:      1.2,
+		LikeRatio: 0.3,
+		Mix:       loadgen.Mix{Put: 1},
+		Seed:      1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	likes := 0
+	const n = 10000
+	for i := 0; i < n; i++ {
+		c, _ := s.Next()
+		req := c.Request.(*pb.PutDecisionRequest)
+		if req.GetActorUserId() == req.GetRecipientUserId() {
+			t.Fatalf("generated a self decision: %v", req)
+		}
+		if req.GetLikedRecipient() {
+			likes++
+		}
+	}
+	if ratio := float64(likes) / n; ratio < 0.27 || ratio > 0.33 {
+		t.Errorf("expected a like ratio near 0.3, got %.3f", ratio)
+	}
+
+	if _, err := loadgen.NewSynthetic(loadgen.SyntheticOptions{Users: 1}); err == nil {
+		t.Error("expected an error for a single user")
+	}
+}
+
+// fakeInvoker fails every third call.
+type fakeInvoker struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeInvoker) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls%3 == 0 {
+		return status.Error(codes.Unavailable, "down")
+	}
+	return nil
+}
+
+func TestRunner(t *testing.T) {
+	calls := []loadgen.Call{
+		{Method: loadgen.MethodCountLikedYou, Request: &pb.CountLikedYouRequest{RecipientUserId: "a"}},
+	}
+	inv := &fakeInvoker{}
+	r := &loadgen.Runner{
+		Invoker:     inv,
+		Source:      loadgen.NewReplay(append(append(calls, calls...), calls...), false),
+		QPS:         1000,
+		Concurrency: 1,
+		Duration:    5 * time.Second,
+	}
+	rep, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ms := rep.Methods()[loadgen.MethodCountLikedYou]
+	if ms == nil || len(ms.Latencies)+rep.Missed != 3 {
+		t.Fatalf("expected 3 calls, got %+v (missed %d)", ms, rep.Missed)
+	}
+	if rep.Missed == 0 && ms.Codes["Unavailable"] != 1 {
+		t.Errorf("expected one failed call, got codes %v", ms.Codes)
+	}
+
+	var buf bytes.Buffer
+	if err := rep.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), loadgen.MethodCountLikedYou) {
+		t.Errorf("report is missing the method:\n%s", buf.String())
+	}
+}
+
+// slowInvoker takes d per call unless ctx ends first.
+type slowInvoker struct{ d time.Duration }
+
+func (s slowInvoker) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	select {
+	case <-time.After(s.d):
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// TestRunner_Drain verifies calls in flight when Duration ends finish
+// instead of being cancelled.
+func TestRunner_Drain(t *testing.T) {
+	calls := []loadgen.Call{
+		{Method: loadgen.MethodCountLikedYou, Request: &pb.CountLikedYouRequest{RecipientUserId: "a"}},
+	}
+	r := &loadgen.Runner{
+		Invoker:     slowInvoker{d: 200 * time.Millisecond},
+		Source:      loadgen.NewReplay(calls, true),
+		QPS:         100,
+		Concurrency: 4,
+		Duration:    50 * time.Millisecond,
+	}
+	rep, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ms := rep.Methods()[loadgen.MethodCountLikedYou]
+	if ms == nil || ms.Codes["OK"] == 0 || ms.Codes["OK"] != len(ms.Latencies) {
+		t.Errorf("expected every call to finish OK, got %+v", ms)
+	}
+}
+
+// slowSource takes d to produce each call.
+type slowSource struct{ d time.Duration }
+
+func (s slowSource) Next() (loadgen.Call, error) {
+	time.Sleep(s.d)
+	return loadgen.Call{Method: loadgen.MethodCountLikedYou, Request: &pb.CountLikedYouRequest{RecipientUserId: "a"}}, nil
+}
+
+// TestRunner_DroppedTicks verifies slots the ticker drops while the loop is
+// behind are counted as missed.
+func TestRunner_DroppedTicks(t *testing.T) {
+	r := &loadgen.Runner{
+		Invoker:     &fakeInvoker{},
+		Source:      slowSource{d: 10 * time.Millisecond},
+		QPS:         1000,
+		Concurrency: 100,
+		Duration:    100 * time.Millisecond,
+	}
+	rep, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rep.Missed == 0 {
+		t.Error("expected dropped ticks to be counted as missed")
+	}
+}
+
+func TestRunner_InvalidQPS(t *testing.T) {
+	for _, qps := range []float64{0, -1, math.NaN(), 1e12, math.Inf(1)} {
+		r := &loadgen.Runner{Invoker: &fakeInvoker{}, Source: slowSource{}, QPS: qps}
+		if _, err := r.Run(context.Background()); err == nil {
+			t.Errorf("expected an error for QPS %g", qps)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	ms := &loadgen.MethodStats{}
+	for i := 1; i <= 100; i++ {
+		ms.Latencies = append(ms.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	if got := ms.Percentile(50); got != 50*time.Millisecond {
+		t.Errorf("expected p50 of 50ms, got %v", got)
+	}
+	if got := ms.Percentile(99); got != 99*time.Millisecond {
+		t.Errorf("expected p99 of 99ms, got %v", got)
+	}
+	if got := ms.Percentile(100); got != 100*time.Millisecond {
+		t.Errorf("expected max of 100ms, got %v", got)
+	}
+}
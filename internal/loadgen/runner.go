@@ -0,0 +1,240 @@
+// Package loadgen drives ExploreService at a target rate from recorded or
+// synthetic traffic and summarizes latency and errors.
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Invoker sends one unary call; *grpc.ClientConn implements it.
+type Invoker interface {
+	Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error
+}
+
+// Runner paces calls from a Source.
+type Runner struct {
+	Invoker Invoker
+	Source  Source
+	// QPS is the target rate. Calls that cannot start on time because all
+	// workers are busy, or because the pacing loop fell behind, are dropped
+	// and counted as missed.
+	QPS float64
+	// Duration bounds how long calls are started; the run also ends when the
+	// source is exhausted. Calls in flight at the end are left to finish.
+	Duration time.Duration
+	// Concurrency is the number of calls that may be in flight.
+	Concurrency int
+	// Timeout bounds each call.
+	Timeout time.Duration
+}
+
+// Run sends calls until ctx is done, Duration elapses or the source ends,
+// then waits for calls in flight. Only ctx cancels them.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	if !(r.QPS > 0) {
+		return nil, errors.New("QPS must be positive")
+	}
+	interval := time.Duration(float64(time.Second) / r.QPS)
+	if interval <= 0 {
+		return nil, fmt.Errorf("QPS %g is too high to pace; the limit is 1e9", r.QPS)
+	}
+	workers := r.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	dispatchCtx := ctx
+	if r.Duration > 0 {
+		var cancel context.CancelFunc
+		dispatchCtx, cancel = context.WithTimeout(ctx, r.Duration)
+		defer cancel()
+	}
+
+	report := newReport()
+	calls := make(chan Call)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range calls {
+				report.record(c.Method, r.invoke(ctx, c))
+			}
+		}()
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var slot int64
+	var sourceErr error
+loop:
+	for {
+		select {
+		case <-dispatchCtx.Done():
+			break loop
+		case tick := <-ticker.C:
+			// The ticker drops ticks while this loop is behind. The calls
+			// due in those slots are skipped and counted as missed.
+			due := max(int64(tick.Sub(start)/interval), slot+1)
+			for ; slot+1 < due; slot++ {
+				if _, err := r.Source.Next(); err != nil {
+					sourceErr = sourceError(err)
+					break loop
+				}
+				report.miss()
+			}
+			slot = due
+		}
+		c, err := r.Source.Next()
+		if err != nil {
+			sourceErr = sourceError(err)
+			break
+		}
+		select {
+		case calls <- c:
+		default:
+			report.miss()
+		}
+	}
+	close(calls)
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+	return report, sourceErr
+}
+
+// sourceError drops io.EOF, which ends a run normally.
+func sourceError(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// outcome is the result of one call.
+type outcome struct {
+	latency time.Duration
+	code    string
+}
+
+func (r *Runner) invoke(ctx context.Context, c Call) outcome {
+	_, resp, _ := newMessages(c.Method)
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := r.Invoker.Invoke(ctx, c.Method, c.Request, resp)
+	return outcome{latency: time.Since(start), code: status.Code(err).String()}
+}
+
+// Report aggregates outcomes per method.
+type Report struct {
+	mu      sync.Mutex
+	methods map[string]*MethodStats
+	Missed  int
+	Elapsed time.Duration
+}
+
+// MethodStats holds one method's latencies and status codes.
+type MethodStats struct {
+	Latencies []time.Duration
+	Codes     map[string]int
+}
+
+func newReport() *Report {
+	return &Report{methods: make(map[string]*MethodStats)}
+}
+
+func (rep *Report) record(method string, o outcome) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	ms, ok := rep.methods[method]
+	if !ok {
+		ms = &MethodStats{Codes: make(map[string]int)}
+		rep.methods[method] = ms
+	}
+	ms.Latencies = append(ms.Latencies, o.latency)
+	ms.Codes[o.code]++
+}
+
+func (rep *Report) miss() {
+	rep.mu.Lock()
+	rep.Missed++
+	rep.mu.Unlock()
+}
+
+// Methods returns per-method stats keyed by full method name.
+func (rep *Report) Methods() map[string]*MethodStats {
+	return rep.methods
+}
+
+// Percentile returns the latency at p (0-100) using the nearest-rank method.
+func (ms *MethodStats) Percentile(p float64) time.Duration {
+	if len(ms.Latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), ms.Latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// ErrorRate is the share of calls that did not return OK.
+func (ms *MethodStats) ErrorRate() float64 {
+	if len(ms.Latencies) == 0 {
+		return 0
+	}
+	return 1 - float64(ms.Codes["OK"])/float64(len(ms.Latencies))
+}
+
+// Write prints a table with one row per method.
+func (rep *Report) Write(w io.Writer) error {
+	names := make([]string, 0, len(rep.methods))
+	for name := range rep.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tCALLS\tQPS\tP50\tP90\tP99\tMAX\tERRORS\tCODES")
+	for _, name := range names {
+		ms := rep.methods[name]
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%s\t%s\t%s\t%s\t%.2f%%\t%s\n",
+			name, len(ms.Latencies), float64(len(ms.Latencies))/rep.Elapsed.Seconds(),
+			ms.Percentile(50), ms.Percentile(90), ms.Percentile(99), ms.Percentile(100),
+			100*ms.ErrorRate(), formatCodes(ms.Codes))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "elapsed %s, %d calls missed their slot\n", rep.Elapsed.Round(time.Millisecond), rep.Missed)
+	return err
+}
+
+func formatCodes(codes map[string]int) string {
+	names := make([]string, 0, len(codes))
+	for c := range codes {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	out := ""
+	for i, c := range names {
+		if i > 0 {
+			out += ","
+		}
+		out += fmt.Sprintf("%s=%d", c, codes[c])
+	}
+	return out
+}
@@ -0,0 +1,203 @@
+package loadgen
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/KEdore/explore/proto"
+)
+
+// Full method names of the ExploreService RPCs.
+const (
+	MethodPutDecision         = pb.ExploreService_PutDecision_FullMethodName
+	MethodListLikedYou        = pb.ExploreService_ListLikedYou_FullMethodName
+	MethodListNewLikedYou     = pb.ExploreService_ListNewLikedYou_FullMethodName
+	MethodCountLikedYou       = pb.ExploreService_CountLikedYou_FullMethodName
+	MethodMarkLikesSeen       = pb.ExploreService_MarkLikesSeen_FullMethodName
+	MethodCountUnseenLikedYou = pb.ExploreService_CountUnseenLikedYou_FullMethodName
+)
+
+// Call is one RPC to send.
+type Call struct {
+	Method  string
+	Request proto.Message
+}
+
+// Source yields calls until io.EOF. Implementations must be safe for
+// concurrent use.
+type Source interface {
+	Next() (Call, error)
+}
+
+// newMessages returns empty request and response messages for method.
+func newMessages(method string) (req, resp proto.Message, ok bool) {
+	switch method {
+	case MethodPutDecision:
+		return &pb.PutDecisionRequest{}, &pb.PutDecisionResponse{}, true
+	case MethodListLikedYou, MethodListNewLikedYou:
+		return &pb.ListLikedYouRequest{}, &pb.ListLikedYouResponse{}, true
+	case MethodCountLikedYou, MethodCountUnseenLikedYou:
+		return &pb.CountLikedYouRequest{}, &pb.CountLikedYouResponse{}, true
+	case MethodMarkLikesSeen:
+		return &pb.MarkLikesSeenRequest{}, &pb.MarkLikesSeenResponse{}, true
+	}
+	return nil, nil, false
+}
+
+// recordedCall is the part of a recorded traffic line that replay needs.
+// Other fields, such as the recorded response, are ignored.
+type recordedCall struct {
+	Method  string          `json:"method"`
+	Request json.RawMessage `json:"request"`
+}
+
+// ReadRecording parses recorded traffic: JSON Lines with a full "method"
+// name and the "request" message in protojson. Lines for unknown methods
+// are skipped.
+func ReadRecording(r io.Reader) ([]Call, error) {
+	var calls []Call
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rc recordedCall
+		if err := json.Unmarshal(sc.Bytes(), &rc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		req, _, ok := newMessages(rc.Method)
+		if !ok {
+			continue
+		}
+		if err := protojson.Unmarshal(rc.Request, req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		calls = append(calls, Call{Method: rc.Method, Request: req})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 {
+		return nil, errors.New("recording holds no ExploreService calls")
+	}
+	return calls, nil
+}
+
+// Replay yields recorded calls in order, starting over when Loop is set.
+type Replay struct {
+	mu    sync.Mutex
+	calls []Call
+	next  int
+	loop  bool
+}
+
+// NewReplay replays calls once, or forever when loop is true.
+func NewReplay(calls []Call, loop bool) *Replay {
+	return &Replay{calls: calls, loop: loop}
+}
+
+func (r *Replay) Next() (Call, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next == len(r.calls) {
+		if !r.loop {
+			return Call{}, io.EOF
+		}
+		r.next = 0
+	}
+	c := r.calls[r.next]
+	r.next++
+	return c, nil
+}
+
+// Mix weights the RPCs in synthetic traffic.
+type Mix struct {
+	Put, List, ListNew, Count int
+}
+
+// DefaultMix is write-heavy, as swiping dominates real traffic.
+var DefaultMix = Mix{Put: 70, List: 10, ListNew: 10, Count: 10}
+
+// SyntheticOptions shapes generated traffic.
+type SyntheticOptions struct {
+	// Users is the size of the user population, named user-0 to user-<Users-1>.
+	Users int
+	// Skew is the Zipf exponent for picking users; values above 1 concentrate
+	// traffic on a few popular users, and 0 picks uniformly.
+	Skew float64
+	// LikeRatio is the share of decisions that are likes.
+	LikeRatio float64
+	Mix       Mix
+	Seed      int64
+}
+
+// Synthetic generates an endless stream of random calls.
+type Synthetic struct {
+	mu   sync.Mutex
+	opts SyntheticOptions
+	rnd  *rand.Rand
+	zipf *rand.Zipf
+}
+
+// NewSynthetic validates opts and returns a generator.
+func NewSynthetic(opts SyntheticOptions) (*Synthetic, error) {
+	if opts.Users < 2 {
+		return nil, errors.New("synthetic traffic needs at least two users")
+	}
+	if opts.LikeRatio < 0 || opts.LikeRatio > 1 {
+		return nil, errors.New("like ratio must be between 0 and 1")
+	}
+	if opts.Mix.Put+opts.Mix.List+opts.Mix.ListNew+opts.Mix.Count <= 0 {
+		opts.Mix = DefaultMix
+	}
+	s := &Synthetic{opts: opts, rnd: rand.New(rand.NewSource(opts.Seed))}
+	if opts.Skew > 1 {
+		s.zipf = rand.NewZipf(s.rnd, opts.Skew, 1, uint64(opts.Users-1))
+	}
+	return s, nil
+}
+
+func (s *Synthetic) user() string {
+	if s.zipf != nil {
+		return "user-" + strconv.FormatUint(s.zipf.Uint64(), 10)
+	}
+	return "user-" + strconv.Itoa(s.rnd.Intn(s.opts.Users))
+}
+
+func (s *Synthetic) Next() (Call, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.opts.Mix
+	pick := s.rnd.Intn(m.Put + m.List + m.ListNew + m.Count)
+	switch {
+	case pick < m.Put:
+		actor, recipient := s.user(), s.user()
+		for recipient == actor {
+			recipient = s.user()
+		}
+		return Call{Method: MethodPutDecision, Request: &pb.PutDecisionRequest{
+			ActorUserId:     actor,
+			RecipientUserId: recipient,
+			LikedRecipient:  s.rnd.Float64() < s.opts.LikeRatio,
+		}}, nil
+	case pick < m.Put+m.List:
+		return Call{Method: MethodListLikedYou, Request: &pb.ListLikedYouRequest{RecipientUserId: s.user()}}, nil
+	case pick < m.Put+m.List+m.ListNew:
+		return Call{Method: MethodListNewLikedYou, Request: &pb.ListLikedYouRequest{RecipientUserId: s.user()}}, nil
+	default:
+		return Call{Method: MethodCountLikedYou, Request: &pb.CountLikedYouRequest{RecipientUserId: s.user()}}, nil
+	}
+}
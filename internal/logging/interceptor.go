@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLen bounds caller-supplied request IDs before they reach the logs.
+const maxRequestIDLen = 128
+
+type actorRequest interface{ GetActorUserId() string }
+type recipientRequest interface{ GetRecipientUserId() string }
+
+// UnaryServerInterceptor assigns each RPC a request ID (reusing the caller's
+// x-request-id when present), echoes it in the response headers, and logs the
+// outcome with its method, duration, status code and user IDs.
+func UnaryServerInterceptor(users UserIDs) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(RequestIDHeader); len(v) > 0 && len(v[0]) <= maxRequestIDLen {
+				id = v[0]
+			}
+		}
+		if id == "" {
+			id = NewRequestID()
+		}
+		ctx = WithRequestID(ctx, id)
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("code", code.String()),
+			slog.String("request_id", id),
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+		if r, ok := req.(actorRequest); ok {
+			attrs = append(attrs, slog.String("actor_user_id", users.Format(r.GetActorUserId())))
+		}
+		if r, ok := req.(recipientRequest); ok {
+			attrs = append(attrs, slog.String("recipient_user_id", users.Format(r.GetRecipientUserId())))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		slog.LogAttrs(ctx, levelFor(code), "rpc finished", attrs...)
+		return resp, err
+	}
+}
+
+// levelFor logs caller mistakes as warnings and server-side failures as errors.
+func levelFor(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition,
+		codes.ResourceExhausted, codes.OutOfRange:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
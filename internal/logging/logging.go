@@ -0,0 +1,121 @@
+// Package logging configures slog and correlates log lines with RPCs.
+package logging
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// Options configures the process-wide logger.
+type Options struct {
+	// Level is one of debug, info, warn or error.
+	Level string
+	// Format is json or text.
+	Format string
+}
+
+// New builds a logger writing to w.
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", opts.Level)
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(opts.Format) {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", opts.Format)
+	}
+}
+
+// Setup installs a logger built from opts as the slog default. Output from the
+// standard log package is routed through it as well.
+func Setup(w io.Writer, opts Options) error {
+	logger, err := New(w, opts)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	return nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request ID in ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 128-bit identifier in hex.
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// User ID privacy modes.
+const (
+	UserIDPlain  = "plain"
+	UserIDHash   = "hash"
+	UserIDRedact = "redact"
+)
+
+// UserIDs controls how user IDs appear in logs.
+type UserIDs struct {
+	mode string
+	salt []byte
+}
+
+// NewUserIDs returns a user ID formatter for mode. Hashes are keyed with salt so
+// they cannot be reversed by hashing candidate IDs without it, so hash mode
+// requires one.
+func NewUserIDs(mode, salt string) (UserIDs, error) {
+	switch mode {
+	case UserIDHash:
+		if salt == "" {
+			return UserIDs{}, errors.New("hashed user IDs require a salt")
+		}
+		return UserIDs{mode: mode, salt: []byte(salt)}, nil
+	case UserIDPlain, UserIDRedact:
+		return UserIDs{mode: mode, salt: []byte(salt)}, nil
+	default:
+		return UserIDs{}, fmt.Errorf("invalid user ID logging mode %q", mode)
+	}
+}
+
+// Format renders id according to the privacy mode.
+func (u UserIDs) Format(id string) string {
+	if id == "" {
+		return ""
+	}
+	switch u.mode {
+	case UserIDPlain:
+		return id
+	case UserIDHash:
+		mac := hmac.New(sha256.New, u.salt)
+		mac.Write([]byte(id))
+		return hex.EncodeToString(mac.Sum(nil))[:16]
+	default:
+		return "[redacted]"
+	}
+}
@@ -0,0 +1,97 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/logging"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// TestUnaryServerInterceptor verifies the fields of the per-RPC log line.
+func TestUnaryServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Level: "info", Format: "json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(prev)
+
+	users, err := logging.NewUserIDs(logging.UserIDHash, "salt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(logging.RequestIDHeader, "req-123"))
+	req := &pb.PutDecisionRequest{ActorUserId: "alice", RecipientUserId: "bob"}
+	info := &grpc.UnaryServerInfo{FullMethod: "/explore.ExploreService/PutDecision"}
+
+	var seenID string
+	logging.UnaryServerInterceptor(users)(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		seenID = logging.RequestID(ctx)
+		return nil, status.Error(codes.Internal, "insert failed: duplicate")
+	})
+	if seenID != "req-123" {
+		t.Errorf("expected handler to see request ID req-123, got %q", seenID)
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"level":             "ERROR",
+		"msg":               "rpc finished",
+		"method":            info.FullMethod,
+		"code":              "Internal",
+		"request_id":        "req-123",
+		"actor_user_id":     users.Format("alice"),
+		"recipient_user_id": users.Format("bob"),
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, line[k])
+		}
+	}
+	if _, ok := line["duration"]; !ok {
+		t.Error("expected a duration field")
+	}
+	if bytes.Contains(buf.Bytes(), []byte("alice")) {
+		t.Error("expected the actor ID to be hashed")
+	}
+}
+
+// TestUserIDs verifies the privacy modes.
+func TestUserIDs(t *testing.T) {
+	plain, _ := logging.NewUserIDs(logging.UserIDPlain, "")
+	if got := plain.Format("alice"); got != "alice" {
+		t.Errorf("plain: expected alice, got %q", got)
+	}
+	redact, _ := logging.NewUserIDs(logging.UserIDRedact, "")
+	if got := redact.Format("alice"); got != "[redacted]" {
+		t.Errorf("redact: expected [redacted], got %q", got)
+	}
+	a, _ := logging.NewUserIDs(logging.UserIDHash, "one")
+	b, _ := logging.NewUserIDs(logging.UserIDHash, "two")
+	if a.Format("alice") == b.Format("alice") {
+		t.Error("hash: expected different salts to produce different hashes")
+	}
+	if a.Format("alice") != a.Format("alice") {
+		t.Error("hash: expected a stable hash")
+	}
+	if _, err := logging.NewUserIDs(logging.UserIDHash, ""); err == nil {
+		t.Error("expected an error for a hash without a salt")
+	}
+	if _, err := logging.NewUserIDs("base64", ""); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,134 @@
+// Package metrics exposes Prometheus metrics for RPCs, storage and domain events.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "explore"
+
+// Metrics owns a private registry so tests can scrape it in-process without
+// interference from other instances. A nil *Metrics records nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcRequests  *prometheus.CounterVec
+	rpcLatency   *prometheus.HistogramVec
+	queryLatency *prometheus.HistogramVec
+	decisions    *prometheus.CounterVec
+	matches      prometheus.Counter
+	abuseFlags   prometheus.Counter
+}
+
+// New creates and registers all collectors, including the Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_requests_total",
+			Help:      "Handled RPCs by method and gRPC status code.",
+		}, []string{"method", "code"}),
+		rpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "RPC handling latency by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		queryLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database statement latency by operation and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "outcome"}),
+		decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "decisions_total",
+			Help:      "Recorded decisions by type (like or pass).",
+		}, []string{"decision"}),
+		matches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "matches_created_total",
+			Help:      "Likes that completed a mutual like.",
+		}),
+		abuseFlags: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "abuse_flags_total",
+			Help:      "Actors flagged by abuse scoring.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.rpcRequests, m.rpcLatency, m.queryLatency, m.decisions, m.matches, m.abuseFlags,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDBStats exports the connection pool statistics of db on every scrape.
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// UnaryServerInterceptor counts RPCs and records their latency.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.rpcLatency.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		m.rpcRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		return resp, err
+	}
+}
+
+// ObserveQuery records how long a storage operation took.
+func (m *Metrics) ObserveQuery(operation string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	m.queryLatency.WithLabelValues(operation, outcome).Observe(d.Seconds())
+}
+
+// RecordDecision counts a like or pass, and a match when the like was mutual.
+func (m *Metrics) RecordDecision(liked, mutual bool) {
+	if m == nil {
+		return
+	}
+	if !liked {
+		m.decisions.WithLabelValues("pass").Inc()
+		return
+	}
+	m.decisions.WithLabelValues("like").Inc()
+	if mutual {
+		m.matches.Inc()
+	}
+}
+
+// RecordAbuseFlag counts an actor newly flagged by abuse scoring.
+func (m *Metrics) RecordAbuseFlag() {
+	if m == nil {
+		return
+	}
+	m.abuseFlags.Inc()
+}
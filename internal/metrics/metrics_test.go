@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/metrics"
+)
+
+// scrape returns the exposition text served by m.
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+	res, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read scrape: %v", err)
+	}
+	return string(body)
+}
+
+// TestMetrics verifies RPC, query and domain metrics in a single scrape.
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	intercept := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/explore.ExploreService/PutDecision"}
+
+	intercept(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	intercept(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.InvalidArgument, "bad")
+	})
+	m.ObserveQuery("put_decision", 3*time.Millisecond, nil)
+	m.ObserveQuery("check_mutual_like", time.Millisecond, errors.New("boom"))
+	m.RecordDecision(true, true)
+	m.RecordDecision(true, false)
+	m.RecordDecision(false, false)
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`explore_rpc_requests_total{code="OK",method="/explore.ExploreService/PutDecision"} 1`,
+		`explore_rpc_requests_total{code="InvalidArgument",method="/explore.ExploreService/PutDecision"} 1`,
+		`explore_rpc_duration_seconds_count{method="/explore.ExploreService/PutDecision"} 2`,
+		`explore_db_query_duration_seconds_count{operation="put_decision",outcome="ok"} 1`,
+		`explore_db_query_duration_seconds_count{operation="check_mutual_like",outcome="error"} 1`,
+		`explore_decisions_total{decision="like"} 2`,
+		`explore_decisions_total{decision="pass"} 1`,
+		`explore_matches_created_total 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected scrape to contain %q", want)
+		}
+	}
+}
+
+// TestNilMetrics verifies that a nil *Metrics is a no-op.
+func TestNilMetrics(t *testing.T) {
+	var m *metrics.Metrics
+	m.ObserveQuery("put_decision", time.Millisecond, nil)
+	m.RecordDecision(true, true)
+	m.RegisterDBStats(nil, "explore")
+}
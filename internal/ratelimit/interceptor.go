@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/KEdore/explore/internal/apperr"
+)
+
+// RetryAfterHeader tells clients how many seconds to wait before retrying.
+const RetryAfterHeader = "retry-after"
+
+// Rule limits the requests for which Key returns ok, bucketing them by key.
+type Rule struct {
+	// Name identifies the rule in errors and logs, e.g. "caller".
+	Name  string
+	Limit Limit
+	Key   func(ctx context.Context, fullMethod string, req any) (key string, ok bool)
+}
+
+// UnaryServerInterceptor rejects requests that exceed any rule with
+// ResourceExhausted, a RetryInfo detail and a retry-after header. Tokens
+// already taken by earlier rules are refunded on rejection, so rejected calls
+// do not drain shared budgets such as the per-RPC one. Limiter failures are
+// logged and the request is let through.
+func UnaryServerInterceptor(limiter Limiter, rules ...Rule) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		type taken struct {
+			key  string
+			rule Rule
+		}
+		var debited []taken
+		for _, rule := range rules {
+			key, ok := rule.Key(ctx, info.FullMethod, req)
+			if !ok {
+				continue
+			}
+			key = rule.Name + ":" + key
+			d, err := limiter.Allow(ctx, key, rule.Limit)
+			if err != nil {
+				slog.WarnContext(ctx, "rate limiter unavailable", "rule", rule.Name, "error", err)
+				continue
+			}
+			if !d.Allowed {
+				for _, t := range debited {
+					if err := limiter.Refund(ctx, t.key, t.rule.Limit); err != nil {
+						slog.WarnContext(ctx, "rate limiter refund failed", "rule", t.rule.Name, "error", err)
+					}
+				}
+				return nil, rejected(ctx, rule.Name, d.RetryAfter)
+			}
+			debited = append(debited, taken{key: key, rule: rule})
+		}
+		return handler(ctx, req)
+	}
+}
+
+func rejected(ctx context.Context, rule string, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, strconv.Itoa(seconds)))
+	return apperr.ResourceExhausted(apperr.ReasonQuotaExceeded, "rate limit exceeded").
+		With("limit", rule).
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+}
@@ -0,0 +1,104 @@
+// Package ratelimit throttles RPCs with token buckets keyed by method, caller
+// or any other request attribute.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Rate tokens per second, holding at most Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision is the outcome of taking one token.
+type Decision struct {
+	Allowed bool
+	// RetryAfter is how long until a token is available when not allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter takes tokens from the bucket identified by key. Implementations
+// backed by shared storage (e.g. Redis) let limits hold across replicas.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Decision, error)
+	// Refund returns a token taken by an allowed call, capped at the burst.
+	Refund(ctx context.Context, key string, limit Limit) error
+}
+
+// Memory is a per-process Limiter. Idle buckets are dropped once they would
+// have refilled completely, so memory stays proportional to active keys.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Duration
+}
+
+// sweepInterval bounds how often Allow scans for idle buckets.
+const sweepInterval = time.Minute
+
+// NewMemory returns an empty in-memory limiter.
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Allow implements Limiter.
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Decision, error) {
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return Decision{Allowed: true}, nil
+	}
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	}
+	b.full = time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Decision{Allowed: true}, nil
+	}
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	return Decision{RetryAfter: wait}, nil
+}
+
+// Refund implements Limiter.
+func (m *Memory) Refund(_ context.Context, key string, limit Limit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b, ok := m.buckets[key]; ok {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+1)
+	}
+	return nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		if now.Sub(b.last) > b.full {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/ratelimit"
+)
+
+// TestMemory verifies burst capacity, per-key isolation and retry hints.
+func TestMemory(t *testing.T) {
+	m := ratelimit.NewMemory()
+	ctx := context.Background()
+	limit := ratelimit.Limit{Rate: 1, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if d, _ := m.Allow(ctx, "alice", limit); !d.Allowed {
+			t.Fatalf("request %d: expected the burst to be allowed", i)
+		}
+	}
+	d, _ := m.Allow(ctx, "alice", limit)
+	if d.Allowed {
+		t.Fatal("expected the bucket to be empty")
+	}
+	if d.RetryAfter <= 0 || d.RetryAfter > time.Second {
+		t.Errorf("expected a retry hint within one token interval, got %v", d.RetryAfter)
+	}
+	if d, _ := m.Allow(ctx, "bob", limit); !d.Allowed {
+		t.Error("expected other keys to have their own bucket")
+	}
+	if d, _ := m.Allow(ctx, "alice", ratelimit.Limit{}); !d.Allowed {
+		t.Error("expected a zero limit to be unlimited")
+	}
+}
+
+// TestUnaryServerInterceptor verifies the rejection status and details.
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := ratelimit.UnaryServerInterceptor(ratelimit.NewMemory(), ratelimit.Rule{
+		Name:  "actor",
+		Limit: ratelimit.Limit{Rate: 0.5, Burst: 1},
+		Key: func(_ context.Context, _ string, req any) (string, bool) {
+			s, ok := req.(string)
+			return s, ok
+		},
+	})
+	call := func(req any) error {
+		_, err := intercept(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/x/Put"},
+			func(ctx context.Context, req any) (any, error) { return nil, nil })
+		return err
+	}
+
+	if err := call("alice"); err != nil {
+		t.Fatalf("expected the first request to pass, got %v", err)
+	}
+	if err := call(42); err != nil {
+		t.Fatalf("expected requests without a key to pass, got %v", err)
+	}
+
+	st := status.Convert(call("alice"))
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", st.Code())
+	}
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if r, ok := d.(*errdetails.RetryInfo); ok {
+			retry = r
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() <= 0 {
+		t.Errorf("expected a RetryInfo detail, got %v", st.Details())
+	}
+}
+
+// TestUnaryServerInterceptor_Refund verifies that a call rejected by a later
+// rule gives back the tokens earlier rules took.
+func TestUnaryServerInterceptor_Refund(t *testing.T) {
+	intercept := ratelimit.UnaryServerInterceptor(ratelimit.NewMemory(),
+		ratelimit.Rule{
+			Name:  "rpc",
+			Limit: ratelimit.Limit{Rate: 0.01, Burst: 2},
+			Key: func(_ context.Context, method string, _ any) (string, bool) {
+				return method, true
+			},
+		},
+		ratelimit.Rule{
+			Name:  "actor",
+			Limit: ratelimit.Limit{Rate: 0.01, Burst: 1},
+			Key: func(_ context.Context, _ string, req any) (string, bool) {
+				s, ok := req.(string)
+				return s, ok
+			},
+		})
+	call := func(req any) error {
+		_, err := intercept(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/x/Put"},
+			func(ctx context.Context, req any) (any, error) { return nil, nil })
+		return err
+	}
+
+	if err := call("bot"); err != nil {
+		t.Fatalf("expected the first request to pass, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if status.Code(call("bot")) != codes.ResourceExhausted {
+			t.Fatalf("request %d: expected the actor limit to reject", i)
+		}
+	}
+	// The rejected calls must not have used the second per-RPC token.
+	if err := call("alice"); err != nil {
+		t.Errorf("expected the per-RPC budget to be intact, got %v", err)
+	}
+}
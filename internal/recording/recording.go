@@ -0,0 +1,255 @@
+// Package recording samples live RPCs into rotated JSON Lines files that
+// loadgen can replay.
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/KEdore/explore/internal/logging"
+)
+
+// filePrefix names recording files traffic-<UTC time>.jsonl so they sort by age.
+const filePrefix = "traffic-"
+
+// Entry is one recorded call. loadgen replays Method and Request.
+type Entry struct {
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	Request    json.RawMessage `json:"request"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Code       string          `json:"code"`
+	DurationMS float64         `json:"duration_ms"`
+}
+
+// Options configures a Recorder.
+type Options struct {
+	Dir string
+	// SampleRate is the share of calls recorded, from 0 to 1.
+	SampleRate float64
+	// MaxFileBytes rotates to a new file once the current one reaches it.
+	MaxFileBytes int64
+	// MaxFiles is how many files are kept; the oldest are deleted. 0 keeps all.
+	MaxFiles int
+	// UserIDs rewrites every user ID field. Hashing keeps pseudonyms
+	// consistent, so replayed traffic keeps its shape.
+	UserIDs logging.UserIDs
+	// RedactFields are proto field names cleared wherever they appear,
+	// e.g. idempotency_key.
+	RedactFields []string
+	// Skip excludes methods from recording, e.g. admin RPCs.
+	Skip func(fullMethod string) bool
+	// Buffer is how many entries may wait for the writer before new ones are dropped.
+	Buffer int
+}
+
+// Recorder writes sampled calls in the background so recording never slows RPCs.
+type Recorder struct {
+	opts    Options
+	redact  map[protoreflect.Name]bool
+	entries chan Entry
+	done    chan struct{}
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+
+	// Owned by the writer goroutine.
+	file    *os.File
+	written int64
+}
+
+// New creates the directory and starts the writer.
+func New(opts Options) (*Recorder, error) {
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		return nil, fmt.Errorf("sample rate must be between 0 and 1")
+	}
+	if err := os.MkdirAll(opts.Dir, 0o750); err != nil {
+		return nil, err
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 1024
+	}
+	r := &Recorder{
+		opts:    opts,
+		redact:  make(map[protoreflect.Name]bool),
+		entries: make(chan Entry, opts.Buffer),
+		done:    make(chan struct{}),
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, f := range opts.RedactFields {
+		if f = strings.TrimSpace(f); f != "" {
+			r.redact[protoreflect.Name(f)] = true
+		}
+	}
+	go r.run()
+	return r, nil
+}
+
+// Close flushes queued entries and closes the current file.
+func (r *Recorder) Close() error {
+	close(r.entries)
+	<-r.done
+	return nil
+}
+
+func (r *Recorder) sampled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Float64() < r.opts.SampleRate
+}
+
+// UnaryServerInterceptor records a sample of calls after they complete.
+func (r *Recorder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if (r.opts.Skip != nil && r.opts.Skip(info.FullMethod)) || !r.sampled() {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		entry := Entry{
+			Time:       start.UTC(),
+			Method:     info.FullMethod,
+			Code:       status.Code(err).String(),
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		var ok bool
+		if entry.Request, ok = r.encode(req); !ok {
+			return resp, err
+		}
+		if err == nil {
+			entry.Response, _ = r.encode(resp)
+		}
+		select {
+		case r.entries <- entry:
+		default:
+			slog.DebugContext(ctx, "traffic recording buffer full, dropping entry", "event", "recording.dropped")
+		}
+		return resp, err
+	}
+}
+
+// encode redacts a copy of msg and marshals it as protojson.
+func (r *Recorder) encode(msg any) (json.RawMessage, bool) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, false
+	}
+	m = proto.Clone(m)
+	r.scrub(m.ProtoReflect())
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// scrub rewrites user ID fields and clears redacted fields, recursively.
+func (r *Recorder) scrub(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case r.redact[fd.Name()]:
+			m.Clear(fd)
+		case fd.Kind() == protoreflect.StringKind && isUserIDField(fd.Name()):
+			if fd.IsList() {
+				list := v.List()
+				for i := 0; i < list.Len(); i++ {
+					list.Set(i, protoreflect.ValueOfString(r.opts.UserIDs.Format(list.Get(i).String())))
+				}
+			} else {
+				m.Set(fd, protoreflect.ValueOfString(r.opts.UserIDs.Format(v.String())))
+			}
+		case fd.Kind() == protoreflect.MessageKind && fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				r.scrub(list.Get(i).Message())
+			}
+		case fd.Kind() == protoreflect.MessageKind && !fd.IsMap():
+			r.scrub(v.Message())
+		}
+		return true
+	})
+}
+
+// isUserIDField matches actor_user_id, recipient_user_id, user_id,
+// actor_user_ids and actor_id.
+func isUserIDField(name protoreflect.Name) bool {
+	n := string(name)
+	return strings.HasSuffix(n, "user_id") || strings.HasSuffix(n, "user_ids") || n == "actor_id"
+}
+
+// run writes entries until Close.
+func (r *Recorder) run() {
+	defer close(r.done)
+	for e := range r.entries {
+		if err := r.write(e); err != nil {
+			slog.Warn("writing traffic recording failed", "event", "recording.write_failed", "error", err)
+		}
+	}
+	if r.file != nil {
+		r.file.Close()
+	}
+}
+
+func (r *Recorder) write(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if r.file == nil || (r.opts.MaxFileBytes > 0 && r.written+int64(len(b)) > r.opts.MaxFileBytes) {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.file.Write(b)
+	r.written += int64(n)
+	return err
+}
+
+// rotate closes the current file, opens a new one and prunes old files.
+func (r *Recorder) rotate() error {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	name := filePrefix + time.Now().UTC().Format("20060102T150405.000000000") + ".jsonl"
+	f, err := os.OpenFile(filepath.Join(r.opts.Dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	r.file, r.written = f, 0
+	return r.prune()
+}
+
+func (r *Recorder) prune() error {
+	if r.opts.MaxFiles <= 0 {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(r.opts.Dir, filePrefix+"*.jsonl"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for len(files) > r.opts.MaxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
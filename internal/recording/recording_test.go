@@ -0,0 +1,136 @@
+package recording_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/KEdore/explore/internal/loadgen"
+	"github.com/KEdore/explore/internal/logging"
+	"github.com/KEdore/explore/internal/recording"
+	pb "github.com/KEdore/explore/proto"
+)
+
+func newRecorder(t *testing.T, opts recording.Options) *recording.Recorder {
+	t.Helper()
+	users, err := logging.NewUserIDs(logging.UserIDHash, "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.UserIDs = users
+	r, err := recording.New(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return r
+}
+
+func call(t *testing.T, r *recording.Recorder, method string, req, resp proto.Message) {
+	t.Helper()
+	handler := func(context.Context, any) (any, error) { return resp, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	if _, err := r.UnaryServerInterceptor()(context.Background(), req, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func recordings(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "traffic-*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// TestRecorder verifies recorded calls are pseudonymised, redacted and replayable.
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	r := newRecorder(t, recording.Options{
+		Dir:          dir,
+		SampleRate:   1,
+		RedactFields: []string{"idempotency_key"},
+		Skip:         func(m string) bool { return strings.HasPrefix(m, "/explore.ExploreAdminService/") },
+	})
+	key := "key-1"
+	call(t, r, loadgen.MethodPutDecision,
+		&pb.PutDecisionRequest{ActorUserId: "alice", RecipientUserId: "bob", LikedRecipient: true, IdempotencyKey: &key},
+		&pb.PutDecisionResponse{MutualLikes: true})
+	call(t, r, loadgen.MethodListLikedYou,
+		&pb.ListLikedYouRequest{RecipientUserId: "bob"},
+		&pb.ListLikedYouResponse{Likers: []*pb.ListLikedYouResponse_Liker{{ActorId: "alice"}}})
+	call(t, r, "/explore.ExploreAdminService/EraseUser", &pb.EraseUserRequest{UserId: "alice"}, &pb.EraseUserResponse{})
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := recordings(t, dir)
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, leaked := range []string{"alice", "bob", "key-1", "ExploreAdminService"} {
+		if strings.Contains(text, leaked) {
+			t.Errorf("recording contains %q:\n%s", leaked, text)
+		}
+	}
+	if !strings.Contains(text, `"code":"OK"`) || !strings.Contains(text, `"response":`) {
+		t.Errorf("expected code and response in recording:\n%s", text)
+	}
+
+	calls, err := loadgen.ReadRecording(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(calls))
+	}
+	put := calls[0].Request.(*pb.PutDecisionRequest)
+	list := calls[1].Request.(*pb.ListLikedYouRequest)
+	if put.IdempotencyKey != nil {
+		t.Errorf("expected idempotency key to be cleared, got %q", put.GetIdempotencyKey())
+	}
+	if put.RecipientUserId != list.RecipientUserId {
+		t.Errorf("expected consistent pseudonyms, got %q and %q", put.RecipientUserId, list.RecipientUserId)
+	}
+}
+
+// TestRecorderRotation verifies files rotate by size and old files are pruned.
+func TestRecorderRotation(t *testing.T) {
+	dir := t.TempDir()
+	r := newRecorder(t, recording.Options{Dir: dir, SampleRate: 1, MaxFileBytes: 1, MaxFiles: 2})
+	for i := 0; i < 5; i++ {
+		call(t, r, loadgen.MethodCountLikedYou, &pb.CountLikedYouRequest{RecipientUserId: "bob"}, &pb.CountLikedYouResponse{})
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files := recordings(t, dir); len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+}
+
+// TestRecorderSampleRate verifies a zero rate records nothing.
+func TestRecorderSampleRate(t *testing.T) {
+	dir := t.TempDir()
+	r := newRecorder(t, recording.Options{Dir: dir})
+	call(t, r, loadgen.MethodCountLikedYou, &pb.CountLikedYouRequest{RecipientUserId: "bob"}, &pb.CountLikedYouResponse{})
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files := recordings(t, dir); len(files) != 0 {
+		t.Fatalf("expected no files, got %d", len(files))
+	}
+	if _, err := recording.New(recording.Options{Dir: dir, SampleRate: 2}); err == nil {
+		t.Fatal("expected error for sample rate above 1")
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/KEdore/explore/internal/auth"
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// authInterceptors returns the authentication interceptor for cfg.AuthMode,
+// if any, followed by the admin gate. The gate is always installed so that
+// ExploreAdminService fails closed: without authentication no caller is a
+// service principal and every admin RPC is denied.
+func authInterceptors(cfg *config.Config) ([]grpc.UnaryServerInterceptor, error) {
+	authn, err := authInterceptor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	gate := auth.RequireService(adminMethod)
+	if authn == nil {
+		return []grpc.UnaryServerInterceptor{gate}, nil
+	}
+	return []grpc.UnaryServerInterceptor{authn, gate}, nil
+}
+
+// authInterceptor builds the authentication interceptor for the mechanisms
+// listed in cfg.AuthMode, or returns nil when authentication is disabled.
+func authInterceptor(cfg *config.Config) (grpc.UnaryServerInterceptor, error) {
+	var authenticators []auth.Authenticator
+	for _, mode := range cfg.AuthMode {
+		switch strings.TrimSpace(mode) {
+		case "", "none":
+		case "jwt":
+			jwtAuth, err := auth.NewJWTAuthenticator(auth.JWTOptions{
+				HS256Secret:  []byte(cfg.AuthJWTHS256Secret),
+				JWKSFile:     cfg.AuthJWTJWKSFile,
+				Issuer:       cfg.AuthJWTIssuer,
+				Audience:     cfg.AuthJWTAudience,
+				ServiceScope: cfg.AuthServiceScope,
+			})
+			if err != nil {
+				return nil, err
+			}
+			authenticators = append(authenticators, jwtAuth)
+		case "mtls":
+			if cfg.TLSClientAuth == "" || cfg.TLSClientAuth == "none" {
+				return nil, fmt.Errorf("AUTH_MODE mtls needs TLS_CLIENT_AUTH request or require")
+			}
+			authenticators = append(authenticators, auth.MTLSAuthenticator{ServiceIdentities: cfg.AuthMTLSServiceIdentities})
+		default:
+			return nil, fmt.Errorf("unknown AUTH_MODE %q", mode)
+		}
+	}
+	if len(authenticators) == 0 {
+		return nil, nil
+	}
+	return auth.UnaryServerInterceptor(auth.Chain(authenticators...), service.OwnerOf, unauthenticatedMethod), nil
+}
+
+// unauthenticatedMethod lets health checks and reflection through without credentials.
+func unauthenticatedMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") ||
+		strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
+
+// adminMethod reports whether fullMethod belongs to ExploreAdminService.
+func adminMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+pb.ExploreAdminService_ServiceDesc.ServiceName+"/")
+}
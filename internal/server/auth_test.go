@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// TestAuthInterceptors_AdminFailsClosed verifies that with no authentication
+// mode configured, a served ExploreAdminService denies every call before it
+// reaches the handler.
+func TestAuthInterceptors_AdminFailsClosed(t *testing.T) {
+	interceptors, err := authInterceptors(&config.Config{AuthMode: []string{"none"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	// The handlers must never run, so the server has no database.
+	pb.RegisterExploreAdminServiceServer(srv, service.NewAdminServer(service.NewExploreServer(nil)))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewExploreAdminServiceClient(conn)
+	ctx := context.Background()
+
+	_, err = client.EraseUser(ctx, &pb.EraseUserRequest{UserId: "alice"})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("expected EraseUser to be PermissionDenied, got %v", err)
+	}
+	_, err = client.ClearAbuseFlag(ctx, &pb.ClearAbuseFlagRequest{ActorUserId: "alice"})
+	if code := status.Code(err); code != codes.PermissionDenied {
+		t.Errorf("expected ClearAbuseFlag to be PermissionDenied, got %v", err)
+	}
+}
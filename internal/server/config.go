@@ -0,0 +1,50 @@
+package server
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/logging"
+)
+
+// checkConfig rejects combinations of settings that would each be valid on
+// their own but silently misbehave together.
+func checkConfig(cfg *config.Config) error {
+	// Liked-you lists and counts read only decisions, so a like must expire
+	// before it can be archived.
+	if cfg.DecisionArchiveAfter > 0 &&
+		(cfg.DecisionLikeTTL <= 0 || cfg.DecisionArchiveAfter < cfg.DecisionLikeTTL) {
+		return errors.New("DECISION_ARCHIVE_AFTER requires DECISION_LIKE_TTL and must be at least as long")
+	}
+	if cfg.LogUserIDs == logging.UserIDHash && cfg.LogUserIDSalt == "" {
+		return errors.New("LOG_USER_IDS=hash requires LOG_USER_ID_SALT")
+	}
+	// TLS is enabled by TLS_CERT_FILE alone; the other settings would be
+	// ignored and the listener would serve plaintext.
+	if cfg.TLSCertFile == "" &&
+		(cfg.TLSKeyFile != "" || cfg.TLSClientCAFile != "" || (cfg.TLSClientAuth != "" && cfg.TLSClientAuth != "none")) {
+		return errors.New("TLS_KEY_FILE, TLS_CLIENT_CA_FILE and TLS_CLIENT_AUTH require TLS_CERT_FILE")
+	}
+	return nil
+}
+
+// recordingUserIDs builds the formatter for user IDs in recorded traffic.
+// Redacted IDs fail USER_ID_PATTERN on replay, so that mode is refused. Without
+// a configured salt, hashes are keyed with a random one for this process.
+func recordingUserIDs(cfg *config.Config) (logging.UserIDs, error) {
+	salt := cfg.RecordingUserIDSalt
+	switch cfg.RecordingUserIDs {
+	case logging.UserIDRedact:
+		return logging.UserIDs{}, errors.New("RECORDING_USER_IDS=redact cannot be replayed; use hash or plain")
+	case logging.UserIDHash:
+		if salt == "" {
+			key := make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return logging.UserIDs{}, err
+			}
+			salt = string(key)
+		}
+	}
+	return logging.NewUserIDs(cfg.RecordingUserIDs, salt)
+}
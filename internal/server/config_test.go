@@ -0,0 +1,113 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/logging"
+)
+
+func TestCheckConfig(t *testing.T) {
+	const day = 24 * time.Hour
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{
+			name: "archive after likes expire",
+			cfg:  config.Config{DecisionLikeTTL: 90 * day, DecisionArchiveAfter: 365 * day},
+		},
+		{
+			name: "archive at like expiry",
+			cfg:  config.Config{DecisionLikeTTL: 90 * day, DecisionArchiveAfter: 90 * day},
+		},
+		{
+			name:    "archive without like expiry",
+			cfg:     config.Config{DecisionArchiveAfter: 365 * day},
+			wantErr: true,
+		},
+		{
+			name:    "archive before likes expire",
+			cfg:     config.Config{DecisionLikeTTL: 365 * day, DecisionArchiveAfter: 90 * day},
+			wantErr: true,
+		},
+		{
+			name:    "hashed log user IDs without salt",
+			cfg:     config.Config{LogUserIDs: "hash"},
+			wantErr: true,
+		},
+		{
+			name: "tls with client auth",
+			cfg:  config.Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", TLSClientCAFile: "ca.pem", TLSClientAuth: "require"},
+		},
+		{
+			name: "client auth none without tls",
+			cfg:  config.Config{TLSClientAuth: "none"},
+		},
+		{
+			name:    "client auth without tls",
+			cfg:     config.Config{TLSClientAuth: "require"},
+			wantErr: true,
+		},
+		{
+			name:    "client ca without tls",
+			cfg:     config.Config{TLSClientCAFile: "ca.pem"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConfig(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestCheckConfig_Defaults verifies the server starts with default settings.
+func TestCheckConfig_Defaults(t *testing.T) {
+	t.Setenv("DB_USER", "user")
+	t.Setenv("DB_PASS", "pass")
+	t.Setenv("DB_NAME", "explore")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := checkConfig(cfg); err != nil {
+		t.Errorf("checkConfig() error = %v", err)
+	}
+	if _, err := logging.NewUserIDs(cfg.LogUserIDs, cfg.LogUserIDSalt); err != nil {
+		t.Errorf("NewUserIDs() error = %v", err)
+	}
+}
+
+func TestRecordingUserIDs(t *testing.T) {
+	if _, err := recordingUserIDs(&config.Config{RecordingUserIDs: logging.UserIDRedact}); err == nil {
+		t.Error("expected redact mode to be refused")
+	}
+
+	// Unsalted processes must not share a key.
+	cfg := &config.Config{RecordingUserIDs: logging.UserIDHash}
+	a, err := recordingUserIDs(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := recordingUserIDs(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Format("alice") == b.Format("alice") {
+		t.Error("expected a random salt when none is configured")
+	}
+
+	cfg.RecordingUserIDSalt = "secret"
+	a, _ = recordingUserIDs(cfg)
+	b, _ = recordingUserIDs(cfg)
+	if a.Format("alice") != b.Format("alice") {
+		t.Error("expected the configured salt to be used")
+	}
+}
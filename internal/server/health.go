@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/KEdore/explore/internal/db"
+	"github.com/KEdore/explore/internal/health"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// LivenessService is the grpc.health.v1 service name that reports liveness.
+// It stays SERVING while the process runs, whereas the empty service name and
+// the ExploreService name report readiness.
+const LivenessService = "liveness"
+
+// registerHealth exposes status through the standard gRPC health service.
+func registerHealth(grpcServer *grpc.Server, status *health.Status) *grpchealth.Server {
+	hs := grpchealth.NewServer()
+	hs.SetServingStatus(LivenessService, healthpb.HealthCheckResponse_SERVING)
+	status.OnChange(func(ready bool) {
+		s := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready {
+			s = healthpb.HealthCheckResponse_SERVING
+		}
+		hs.SetServingStatus("", s)
+		hs.SetServingStatus(pb.ExploreService_ServiceDesc.ServiceName, s)
+	})
+	healthpb.RegisterHealthServer(grpcServer, hs)
+	return hs
+}
+
+// prepareSchema applies or checks migrations and keeps the migrations component
+// not ready until the schema is current.
+func prepareSchema(ctx context.Context, database *sql.DB, status *health.Status, migrate bool, interval time.Duration) error {
+	if migrate {
+		if err := db.Migrate(ctx, database); err != nil {
+			return err
+		}
+		status.Set(componentMigrations, true, "")
+		return nil
+	}
+
+	// Someone else owns migrations: wait for them without blocking startup.
+	go func() {
+		for {
+			pending, err := db.PendingMigrations(ctx, database)
+			switch {
+			case err != nil:
+				status.Set(componentMigrations, false, err.Error())
+			case len(pending) > 0:
+				status.Set(componentMigrations, false, fmt.Sprintf("%d pending migrations", len(pending)))
+			default:
+				status.Set(componentMigrations, true, "")
+				return
+			}
+			if interval <= 0 {
+				slog.Warn("schema has pending migrations and MIGRATE_ON_START is disabled")
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+	return nil
+}
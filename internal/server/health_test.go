@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/KEdore/explore/internal/health"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// TestRegisterHealth verifies that readiness drives the gRPC health service
+// while liveness stays SERVING until shutdown.
+func TestRegisterHealth(t *testing.T) {
+	status := health.NewStatus(componentDatabase, componentMigrations)
+	hs := registerHealth(grpc.NewServer(), status)
+	ctx := context.Background()
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		res, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q) failed: %v", service, err)
+		}
+		return res.Status
+	}
+
+	if got := check(LivenessService); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected liveness SERVING, got %v", got)
+	}
+	if got := check(pb.ExploreService_ServiceDesc.ServiceName); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected readiness NOT_SERVING before the database is up, got %v", got)
+	}
+
+	status.Set(componentDatabase, true, "")
+	if got := check(""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected readiness NOT_SERVING with pending migrations, got %v", got)
+	}
+
+	status.Set(componentMigrations, true, "")
+	if got := check(""); got != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected readiness SERVING, got %v", got)
+	}
+
+	hs.Shutdown()
+	for _, svc := range []string{"", LivenessService} {
+		if got := check(svc); got != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("expected %q NOT_SERVING after shutdown, got %v", svc, got)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// purgeBatchSize bounds each batch so background jobs never hold long locks.
+const purgeBatchSize = 1000
+
+// batchJob is background maintenance that works through rows in batches.
+type batchJob struct {
+	run func(ctx context.Context, limit int) (int64, error)
+	// Log messages and events for a failed batch and for a tick's total.
+	failed, failedEvent string
+	done, doneEvent     string
+}
+
+// runBatches runs job every interval until ctx is done. Each tick drains in
+// batches until a batch comes back short.
+func runBatches(ctx context.Context, interval time.Duration, job batchJob) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var total int64
+			for ctx.Err() == nil {
+				n, err := job.run(ctx, purgeBatchSize)
+				total += n
+				if err != nil {
+					slog.Warn(job.failed, "event", job.failedEvent, "error", err)
+					break
+				}
+				if n < purgeBatchSize {
+					break
+				}
+			}
+			if total > 0 {
+				slog.Info(job.done, "event", job.doneEvent, "rows", total)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net"
+	"path"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/KEdore/explore/internal/auth"
+	"github.com/KEdore/explore/internal/config"
+	"github.com/KEdore/explore/internal/ratelimit"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// rateLimitInterceptor builds the per-RPC, per-caller and per-actor limits,
+// or returns nil when rate limiting is disabled.
+func rateLimitInterceptor(cfg *config.Config, limiter ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	if !cfg.RateLimitEnabled {
+		return nil
+	}
+	var rules []ratelimit.Rule
+	for method, rate := range cfg.RateLimitRPC {
+		rules = append(rules, ratelimit.Rule{
+			Name:  "rpc",
+			Limit: ratelimit.Limit{Rate: rate, Burst: max(1, int(rate))},
+			Key: func(_ context.Context, fullMethod string, _ any) (string, bool) {
+				return method, path.Base(fullMethod) == method
+			},
+		})
+	}
+	rules = append(rules,
+		ratelimit.Rule{
+			Name:  "caller",
+			Limit: ratelimit.Limit{Rate: cfg.RateLimitCallerRate, Burst: cfg.RateLimitCallerBurst},
+			Key:   callerKey,
+		},
+		ratelimit.Rule{
+			Name:  "actor",
+			Limit: ratelimit.Limit{Rate: cfg.RateLimitActorRate, Burst: cfg.RateLimitActorBurst},
+			Key: func(_ context.Context, _ string, req any) (string, bool) {
+				r, ok := req.(*pb.PutDecisionRequest)
+				return r.GetActorUserId(), ok
+			},
+		},
+	)
+
+	limit := ratelimit.UnaryServerInterceptor(limiter, rules...)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if unauthenticatedMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		return limit(ctx, req, info, handler)
+	}
+}
+
+// callerKey identifies the authenticated user, or the client IP when
+// authentication is disabled. Service principals act for many users and are
+// only subject to the per-RPC and per-actor limits.
+func callerKey(ctx context.Context, _ string, _ any) (string, bool) {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.Subject, !p.Service
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return host, true
+	}
+	return "", false
+}
@@ -3,50 +3,267 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net"
-	"log"
+	"net/http"
+	"regexp"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/KEdore/explore/internal/abuse"
+	"github.com/KEdore/explore/internal/apperr"
 	"github.com/KEdore/explore/internal/config"
 	"github.com/KEdore/explore/internal/db"
-	pb "github.com/KEdore/explore/proto"
+	"github.com/KEdore/explore/internal/dbconfig"
+	"github.com/KEdore/explore/internal/health"
+	"github.com/KEdore/explore/internal/logging"
+	"github.com/KEdore/explore/internal/metrics"
+	"github.com/KEdore/explore/internal/ratelimit"
+	"github.com/KEdore/explore/internal/recording"
 	"github.com/KEdore/explore/internal/service"
+	"github.com/KEdore/explore/internal/tlsconfig"
+	"github.com/KEdore/explore/internal/tracing"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// Readiness components reported on the health endpoint.
+const (
+	componentDatabase   = "database"
+	componentMigrations = "migrations"
+	componentGRPC       = "grpc"
 )
 
 // RunServer starts a gRPC server with the provided configuration and returns a function to stop the server.
-// It initializes a MySQL database client and sets up the gRPC server to listen on the specified address.
+// It starts the health endpoint first, waits for MySQL with backoff, brings the
+// schema up to date, and then sets up the gRPC server to listen on the specified address.
 func RunServer(ctx context.Context, cfg *config.Config) (stopFunc func(), err error) {
-	database, err := db.NewMySQLClient(cfg.DBUser, cfg.DBPass, cfg.DBHost, cfg.DBName)
+	// closers run in reverse order on shutdown, or immediately if startup fails.
+	var closers []func()
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+	defer func() {
+		if err != nil {
+			closeAll()
+		}
+	}()
+
+	if err := checkConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	status := health.NewStatus(componentDatabase, componentMigrations, componentGRPC)
+	healthServer, err := serveHTTP("Health endpoint", cfg.HealthAddress, status.Handler())
+	if err != nil {
+		return nil, err
+	}
+	closers = append(closers, func() { healthServer.Close() })
+
+	userIDs, err := logging.NewUserIDs(cfg.LogUserIDs, cfg.LogUserIDSalt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_USER_IDS or LOG_USER_ID_SALT: %w", err)
+	}
+
+	userIDPattern, err := regexp.Compile(cfg.UserIDPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid USER_ID_PATTERN: %w", err)
+	}
+
+	authn, err := authInterceptors(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		ServiceName:  cfg.TracingServiceName,
+		Exporter:     cfg.TracingExporter,
+		SampleRatio:  cfg.TracingSampleRatio,
+		OTLPEndpoint: cfg.TracingOTLPEndpoint,
+		OTLPInsecure: cfg.TracingOTLPInsecure,
+	})
+	if err != nil {
+		return nil, err
+	}
+	closers = append(closers, func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("tracing shutdown failed", "error", err)
+		}
+	})
+
+	m := metrics.New()
+	if cfg.MetricsAddress != "" {
+		metricsServer, err := serveHTTP("Metrics endpoint", cfg.MetricsAddress, m.Handler())
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, func() { metricsServer.Close() })
+	}
+
+	database, err := db.Connect(ctx, dbconfig.Options(cfg), dbconfig.RetryPolicy(cfg))
 	if err != nil {
 		return nil, err
 	}
-	// Make sure the database is closed when the server stops.
-	// (You might want to handle this in your shutdown logic.)
+	closers = append(closers, func() { database.Close() })
+	status.Set(componentDatabase, true, "")
+	m.RegisterDBStats(database, cfg.DBName)
+
+	bgCtx, stopBackground := context.WithCancel(ctx)
+	closers = append(closers, stopBackground)
+	if err := prepareSchema(bgCtx, database, status, cfg.MigrateOnStart, cfg.DBMonitorInterval); err != nil {
+		return nil, err
+	}
 
 	lis, err := net.Listen("tcp", cfg.ServerAddress)
 	if err != nil {
 		return nil, err
 	}
+	closers = append(closers, func() { lis.Close() })
+
+	interceptors := []grpc.UnaryServerInterceptor{
+		tracing.UnaryServerInterceptor(nil),
+		logging.UnaryServerInterceptor(userIDs),
+		m.UnaryServerInterceptor(),
+	}
+	if cfg.RecordingEnabled {
+		recordingIDs, err := recordingUserIDs(cfg)
+		if err != nil {
+			return nil, err
+		}
+		recorder, err := recording.New(recording.Options{
+			Dir:          cfg.RecordingDir,
+			SampleRate:   cfg.RecordingSampleRate,
+			MaxFileBytes: cfg.RecordingMaxFileBytes,
+			MaxFiles:     cfg.RecordingMaxFiles,
+			UserIDs:      recordingIDs,
+			RedactFields: cfg.RecordingRedactFields,
+			Skip:         adminMethod,
+		})
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, func() { recorder.Close() })
+		interceptors = append(interceptors, recorder.UnaryServerInterceptor())
+	}
+	interceptors = append(interceptors, apperr.UnaryServerInterceptor())
+	interceptors = append(interceptors, authn...)
+	if limit := rateLimitInterceptor(cfg, ratelimit.NewMemory()); limit != nil {
+		interceptors = append(interceptors, limit)
+	}
+	interceptors = append(interceptors, service.NewValidator(userIDPattern).UnaryServerInterceptor())
 
-	grpcServer := grpc.NewServer()
-	pb.RegisterExploreServiceServer(grpcServer, service.NewExploreServer(database))
+	serverOpts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors...)}
+	if cfg.TLSCertFile != "" {
+		reloader, err := tlsconfig.NewReloader(tlsconfig.Options{
+			CertFile:     cfg.TLSCertFile,
+			KeyFile:      cfg.TLSKeyFile,
+			ClientCAFile: cfg.TLSClientCAFile,
+			ClientAuth:   cfg.TLSClientAuth,
+		})
+		if err != nil {
+			return nil, err
+		}
+		go reloader.Watch(bgCtx, cfg.TLSReloadInterval)
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(reloader.ServerConfig())))
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+	exploreOpts := []service.Option{
+		service.WithMetrics(m),
+		service.WithIdempotency(cfg.IdempotencyTTL),
+		service.WithErasureBatchSize(cfg.ErasureBatchSize),
+		service.WithErasureAuditKey(cfg.ErasureAuditKey),
+		service.WithExpiry(service.ExpiryPolicy{PassTTL: cfg.DecisionPassTTL, LikeTTL: cfg.DecisionLikeTTL}),
+		service.WithArchive(cfg.DecisionArchiveAfter),
+	}
+	if cfg.AbuseDetectionEnabled {
+		exploreOpts = append(exploreOpts, service.WithAbuseDetection(abuse.Policy{
+			MaxLikesPerHour: cfg.AbuseMaxLikesPerHour,
+			MaxLikeRatio:    cfg.AbuseMaxLikeRatio,
+			MinReciprocity:  cfg.AbuseMinReciprocity,
+			MinDecisions:    cfg.AbuseMinDecisions,
+			Threshold:       cfg.AbuseFlagThreshold,
+		}))
+	}
+	explore := service.NewExploreServer(database, exploreOpts...)
+	pb.RegisterExploreServiceServer(grpcServer, explore)
+	pb.RegisterExploreAdminServiceServer(grpcServer, service.NewAdminServer(explore))
+	grpcHealth := registerHealth(grpcServer, status)
 	reflection.Register(grpcServer)
 
+	go db.ReportStats(bgCtx, database, cfg.DBStatsInterval)
+	if cfg.IdempotencyTTL > 0 {
+		go runBatches(bgCtx, cfg.IdempotencyPurgeInterval, batchJob{
+			run:    explore.PurgeExpiredIdempotencyKeys,
+			failed: "purging idempotency keys failed", failedEvent: "idempotency.purge_failed",
+			done: "purged expired idempotency keys", doneEvent: "idempotency.purged",
+		})
+	}
+	if cfg.DecisionPassTTL > 0 || cfg.DecisionLikeTTL > 0 {
+		go runBatches(bgCtx, cfg.DecisionExpiryInterval, batchJob{
+			run:    explore.PurgeExpiredDecisions,
+			failed: "purging expired decisions failed", failedEvent: "expiry.purge_failed",
+			done: "purged expired decisions", doneEvent: "expiry.purged",
+		})
+	}
+	if cfg.DecisionArchiveAfter > 0 {
+		go runBatches(bgCtx, cfg.DecisionArchiveInterval, batchJob{
+			run:    explore.ArchiveDecisions,
+			failed: "archiving decisions failed", failedEvent: "archive.failed",
+			done: "archived cold decisions", doneEvent: "archive.moved",
+		})
+	}
+	go db.Monitor(bgCtx, database, cfg.DBMonitorInterval, func(up bool, err error) {
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		}
+		status.Set(componentDatabase, up, reason)
+	})
+
 	// Run the server in a goroutine.
 	go func() {
-		log.Printf("Server listening at %v", lis.Addr())
+		slog.Info("gRPC server listening", "address", lis.Addr().String())
 		if err := grpcServer.Serve(lis); err != nil {
-			log.Printf("gRPC server error: %v", err)
+			slog.Error("gRPC server failed", "error", err)
 		}
 	}()
+	status.Set(componentGRPC, true, "")
 
 	// Return a shutdown function.
 	stopFunc = func() {
+		// Report NOT_SERVING before draining so load balancers stop routing to us.
+		status.Set(componentGRPC, false, "shutting down")
+		grpcHealth.Shutdown()
+		if cfg.ShutdownDrainDelay > 0 {
+			time.Sleep(cfg.ShutdownDrainDelay)
+		}
 		grpcServer.GracefulStop()
-		database.Close()
-		lis.Close()
+		closeAll()
 	}
 	return stopFunc, nil
 }
+
+// serveHTTP starts an HTTP server on addr in the background.
+func serveHTTP(name, addr string, handler http.Handler) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		slog.Info(name+" listening", "address", lis.Addr().String())
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(name+" failed", "error", err)
+		}
+	}()
+	return srv, nil
+}
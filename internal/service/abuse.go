@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+
+	"github.com/KEdore/explore/internal/abuse"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// WithAbuseDetection scores actors on every PutDecision and flags those that
+// reach the policy threshold, hiding their likes from recipients.
+func WithAbuseDetection(policy abuse.Policy) Option {
+	return func(s *ExploreServer) { s.abusePolicy = &policy }
+}
+
+// recordActivity updates the actor's counters and flags them if their
+// activity now scores as abusive. It is called only when PutDecision changed
+// the stored decision, so repeats do not inflate the counters. The decision
+// is already stored, so failures are logged rather than returned.
+func (s *ExploreServer) recordActivity(ctx context.Context, req *pb.PutDecisionRequest, mutual bool) {
+	if s.abusePolicy == nil {
+		return
+	}
+	if mutual {
+		if err := s.creditMatch(ctx, req.GetRecipientUserId()); err != nil {
+			slog.WarnContext(ctx, "abuse scoring failed", "event", "abuse.score_failed", "error", err)
+		}
+	}
+	if err := s.scoreActor(ctx, req, mutual); err != nil {
+		slog.WarnContext(ctx, "abuse scoring failed", "event", "abuse.score_failed", "error", err)
+	}
+}
+
+// creditMatch counts a new match for the recipient, who liked first. Their
+// reciprocity only improves, so they are not rescored.
+func (s *ExploreServer) creditMatch(ctx context.Context, recipient string) error {
+	query := `
+		INSERT INTO actor_activity (actor_user_id, mutual_likes)
+		VALUES (?, 1)
+		ON DUPLICATE KEY UPDATE mutual_likes = mutual_likes + 1
+	`
+	return s.observe(ctx, "credit_actor_match", func(ctx context.Context) error {
+		_, err := s.db.ExecContext(ctx, query, recipient)
+		return err
+	})
+}
+
+func (s *ExploreServer) scoreActor(ctx context.Context, req *pb.PutDecisionRequest, mutual bool) error {
+	var likes, passes, mutuals int64
+	if req.GetLikedRecipient() {
+		likes = 1
+	} else {
+		passes = 1
+	}
+	if mutual {
+		mutuals = 1
+	}
+
+	// window_start is assigned last so window_likes still sees the old window.
+	activityQuery := `
+		INSERT INTO actor_activity (actor_user_id, likes, passes, mutual_likes, window_start, window_likes)
+		VALUES (?, ?, ?, ?, NOW(), ?)
+		ON DUPLICATE KEY UPDATE
+			likes = likes + VALUES(likes),
+			passes = passes + VALUES(passes),
+			mutual_likes = mutual_likes + VALUES(mutual_likes),
+			window_likes = IF(window_start <= NOW() - INTERVAL 1 HOUR, VALUES(window_likes), window_likes + VALUES(window_likes)),
+			window_start = IF(window_start <= NOW() - INTERVAL 1 HOUR, NOW(), window_start)
+	`
+	actor := req.GetActorUserId()
+	err := s.observe(ctx, "record_actor_activity", func(ctx context.Context) error {
+		_, err := s.db.ExecContext(ctx, activityQuery, actor, likes, passes, mutuals, likes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	statsQuery := `
+		SELECT a.likes, a.passes, a.mutual_likes, a.window_likes,
+			f.actor_user_id IS NOT NULL AND f.cleared_at IS NULL
+		FROM actor_activity a
+		LEFT JOIN abuse_flags f ON f.actor_user_id = a.actor_user_id
+		WHERE a.actor_user_id = ?
+	`
+	var activity abuse.Activity
+	var flagged bool
+	err = s.observe(ctx, "get_actor_activity", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, statsQuery, actor).Scan(
+			&activity.Likes, &activity.Passes, &activity.MutualLikes, &activity.WindowLikes, &flagged)
+	})
+	if err != nil || flagged {
+		return err
+	}
+
+	abusive, score, reasons := s.abusePolicy.Flagged(activity)
+	if !abusive {
+		return nil
+	}
+	// A previously cleared flag is reopened with a fresh flagged_at.
+	flagQuery := `
+		INSERT INTO abuse_flags (actor_user_id, score, reasons)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			score = VALUES(score),
+			reasons = VALUES(reasons),
+			flagged_at = IF(cleared_at IS NULL, flagged_at, NOW()),
+			cleared_at = NULL,
+			cleared_by = NULL
+	`
+	err = s.observe(ctx, "flag_actor", func(ctx context.Context) error {
+		_, err := s.db.ExecContext(ctx, flagQuery, actor, score, strings.Join(reasons, ","))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.metrics.RecordAbuseFlag()
+	// The actor is left to the request log, which applies LOG_USER_IDS.
+	slog.InfoContext(ctx, "actor flagged for abuse", "event", "abuse.flagged", "score", score, "reasons", reasons)
+	return nil
+}
+
+// scanAbuseFlag reads a row of (actor_user_id, score, reasons, flagged_at, cleared_at, cleared_by).
+func scanAbuseFlag(rows *sql.Rows) (*pb.AbuseFlag, error) {
+	var (
+		flag      pb.AbuseFlag
+		reasons   string
+		flaggedAt sql.NullTime
+		clearedAt sql.NullTime
+		clearedBy sql.NullString
+	)
+	if err := rows.Scan(&flag.ActorUserId, &flag.Score, &reasons, &flaggedAt, &clearedAt, &clearedBy); err != nil {
+		return nil, err
+	}
+	if reasons != "" {
+		flag.Reasons = strings.Split(reasons, ",")
+	}
+	if flaggedAt.Valid {
+		flag.FlaggedUnixTimestamp = uint64(flaggedAt.Time.Unix())
+	}
+	if clearedAt.Valid {
+		ts := uint64(clearedAt.Time.Unix())
+		flag.ClearedUnixTimestamp = &ts
+	}
+	if clearedBy.Valid {
+		flag.ClearedBy = &clearedBy.String
+	}
+	return &flag, nil
+}
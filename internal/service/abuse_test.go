@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/abuse"
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+const (
+	activityQuery = `INSERT INTO actor_activity`
+	statsQuery    = `SELECT a.likes, a.passes, a.mutual_likes, a.window_likes`
+	flagQuery     = `INSERT INTO abuse_flags`
+	creditQuery   = `INSERT INTO actor_activity (actor_user_id, mutual_likes)`
+)
+
+func activityRows(likes, passes, mutual, window int64, flagged bool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"likes", "passes", "mutual_likes", "window_likes", "flagged"}).
+		AddRow(likes, passes, mutual, window, flagged)
+}
+
+// TestPutDecision_AbuseScoring verifies that actors are flagged once their
+// activity crosses the policy threshold, and only once.
+func TestPutDecision_AbuseScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		flagged bool
+	}{
+		{name: "regular actor", rows: activityRows(10, 30, 1, 10, false)},
+		{name: "too fast", rows: activityRows(600, 600, 60, 600, false), flagged: true},
+		{name: "already flagged", rows: activityRows(600, 0, 0, 600, true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to open sqlmock database: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectExec(putQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(activityQuery).
+				WithArgs("actor1", int64(0), int64(1), int64(0), int64(0)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(statsQuery).WithArgs("actor1").WillReturnRows(tt.rows)
+			if tt.flagged {
+				mock.ExpectExec(flagQuery).
+					WithArgs("actor1", 0.5, abuse.ReasonLikeVelocity).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			srv := service.NewExploreServer(db, service.WithAbuseDetection(abuse.DefaultPolicy))
+			_, err = srv.PutDecision(context.Background(), &pb.PutDecisionRequest{
+				ActorUserId:     "actor1",
+				RecipientUserId: "recipient1",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+// TestPutDecision_AbuseScoringMatch verifies that a match credits the first
+// liker as well, who is not rescored and so never flagged by it.
+func TestPutDecision_AbuseScoringMatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(putQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions`)).
+		WithArgs("recipient1", "actor1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(creditQuery)).
+		WithArgs("recipient1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(activityQuery).
+		WithArgs("actor1", int64(1), int64(0), int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(statsQuery).WithArgs("actor1").WillReturnRows(activityRows(10, 30, 1, 10, false))
+
+	srv := service.NewExploreServer(db, service.WithAbuseDetection(abuse.DefaultPolicy))
+	res, err := srv.PutDecision(context.Background(), &pb.PutDecisionRequest{
+		ActorUserId:     "actor1",
+		RecipientUserId: "recipient1",
+		LikedRecipient:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.GetMutualLikes() {
+		t.Errorf("expected a mutual like")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPutDecision_AbuseScoringRepeat verifies that repeating a stored
+// decision leaves the counters alone.
+func TestPutDecision_AbuseScoringRepeat(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(putQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions`)).
+		WithArgs("recipient1", "actor1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	srv := service.NewExploreServer(db, service.WithAbuseDetection(abuse.DefaultPolicy))
+	_, err = srv.PutDecision(context.Background(), &pb.PutDecisionRequest{
+		ActorUserId:     "actor1",
+		RecipientUserId: "recipient1",
+		LikedRecipient:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPutDecision_AbuseScoringFailure verifies that scoring errors do not
+// fail a decision that was already stored.
+func TestPutDecision_AbuseScoringFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(putQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(activityQuery).WillReturnError(errors.New("boom"))
+
+	srv := service.NewExploreServer(db, service.WithAbuseDetection(abuse.DefaultPolicy))
+	_, err = srv.PutDecision(context.Background(), &pb.PutDecisionRequest{
+		ActorUserId:     "actor1",
+		RecipientUserId: "recipient1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
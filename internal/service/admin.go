@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/KEdore/explore/internal/apperr"
+	"github.com/KEdore/explore/internal/auth"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// AdminServer implements ExploreAdminService on the storage of an ExploreServer.
+type AdminServer struct {
+	pb.UnimplementedExploreAdminServiceServer
+	s *ExploreServer
+}
+
+// NewAdminServer shares explore's database, metrics and tracer.
+func NewAdminServer(explore *ExploreServer) *AdminServer {
+	return &AdminServer{s: explore}
+}
+
+// ListAbuseFlags returns flagged actors, most recently flagged first.
+func (a *AdminServer) ListAbuseFlags(ctx context.Context, req *pb.ListAbuseFlagsRequest) (*pb.ListAbuseFlagsResponse, error) {
+	offset, err := parseOffset(req.GetPaginationToken())
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT actor_user_id, score, reasons, flagged_at, cleared_at, cleared_by
+		FROM abuse_flags
+		WHERE cleared_at IS NULL OR ?
+		ORDER BY flagged_at DESC, actor_user_id
+		LIMIT ? OFFSET ?
+	`
+	var flags []*pb.AbuseFlag
+	err = a.s.observe(ctx, "list_abuse_flags", func(ctx context.Context) error {
+		rows, err := a.s.db.QueryContext(ctx, query, req.GetIncludeCleared(), DefaultLimit, offset)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			flag, err := scanAbuseFlag(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			flags = append(flags, flag)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration error: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperr.FromDB(err, "failed to list abuse flags")
+	}
+
+	nextToken := ""
+	if len(flags) == DefaultLimit {
+		nextToken = strconv.Itoa(offset + DefaultLimit)
+	}
+	return &pb.ListAbuseFlagsResponse{
+		Flags:               flags,
+		NextPaginationToken: &nextToken,
+	}, nil
+}
+
+// ClearAbuseFlag unhides an actor's likes and resets their counters so the
+// ratio signals start over instead of re-flagging on the next decision. Both
+// happen in one transaction, so a failed reset leaves the flag active and the
+// call can be retried.
+func (a *AdminServer) ClearAbuseFlag(ctx context.Context, req *pb.ClearAbuseFlagRequest) (*pb.ClearAbuseFlagResponse, error) {
+	clearedBy := ""
+	if p, ok := auth.FromContext(ctx); ok {
+		clearedBy = p.Subject
+	}
+
+	clearQuery := `
+		UPDATE abuse_flags SET cleared_at = NOW(), cleared_by = ?
+		WHERE actor_user_id = ? AND cleared_at IS NULL
+	`
+	resetQuery := `DELETE FROM actor_activity WHERE actor_user_id = ?`
+	var cleared bool
+	err := a.s.observe(ctx, "clear_abuse_flag", func(ctx context.Context) error {
+		tx, err := a.s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		res, err := tx.ExecContext(ctx, clearQuery, clearedBy, req.GetActorUserId())
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, resetQuery, req.GetActorUserId()); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		cleared = true
+		return nil
+	})
+	if err != nil {
+		return nil, apperr.FromDB(err, "failed to clear abuse flag")
+	}
+	return &pb.ClearAbuseFlagResponse{Cleared: cleared}, nil
+}
@@ -0,0 +1,209 @@
+package service_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/auth"
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+func TestListAbuseFlags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	flaggedAt := time.Unix(1700000000, 0)
+	clearedAt := time.Unix(1700003600, 0)
+	rows := sqlmock.NewRows([]string{"actor_user_id", "score", "reasons", "flagged_at", "cleared_at", "cleared_by"}).
+		AddRow("actor1", 0.5, "like_velocity", flaggedAt, nil, nil).
+		AddRow("actor2", 0.5, "like_ratio,low_reciprocity", flaggedAt, clearedAt, "ops")
+	mock.ExpectQuery(`SELECT actor_user_id, score, reasons, flagged_at, cleared_at, cleared_by`).
+		WithArgs(true, service.DefaultLimit, 0).
+		WillReturnRows(rows)
+
+	admin := service.NewAdminServer(service.NewExploreServer(db))
+	res, err := admin.ListAbuseFlags(context.Background(), &pb.ListAbuseFlagsRequest{IncludeCleared: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.GetFlags()) != 2 {
+		t.Fatalf("expected 2 flags, got %d", len(res.GetFlags()))
+	}
+	if got := res.GetFlags()[1]; len(got.GetReasons()) != 2 || got.GetClearedBy() != "ops" ||
+		got.GetClearedUnixTimestamp() != uint64(clearedAt.Unix()) {
+		t.Errorf("unexpected cleared flag: %v", got)
+	}
+	if res.GetFlags()[0].ClearedUnixTimestamp != nil {
+		t.Errorf("expected the active flag to have no cleared timestamp")
+	}
+	if res.GetNextPaginationToken() != "" {
+		t.Errorf("expected empty next token, got %q", res.GetNextPaginationToken())
+	}
+}
+
+func TestClearAbuseFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+	}{
+		{name: "active flag", affected: 1},
+		{name: "no active flag", affected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to open sqlmock database: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE abuse_flags SET cleared_at = NOW\(\), cleared_by = \?`).
+				WithArgs("ops", "actor1").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.affected > 0 {
+				mock.ExpectExec(`DELETE FROM actor_activity`).
+					WithArgs("actor1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Service: true})
+			admin := service.NewAdminServer(service.NewExploreServer(db))
+			res, err := admin.ClearAbuseFlag(ctx, &pb.ClearAbuseFlagRequest{ActorUserId: "actor1"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.GetCleared() != (tt.affected > 0) {
+				t.Errorf("expected cleared=%v, got %v", tt.affected > 0, res.GetCleared())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+// TestClearAbuseFlag_ResetFails verifies a failed counter reset rolls back the
+// clear, so a retry still sees the active flag.
+func TestClearAbuseFlag_ResetFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE abuse_flags SET cleared_at`).
+		WithArgs("ops", "actor1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM actor_activity`).
+		WithArgs("actor1").
+		WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Service: true})
+	admin := service.NewAdminServer(service.NewExploreServer(db))
+	if _, err := admin.ClearAbuseFlag(ctx, &pb.ClearAbuseFlagRequest{ActorUserId: "actor1"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestEraseUser verifies that every table is drained in batches and the
+// deleted rows are added to the audit record.
+func TestEraseUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	// The audit record is keyed by an HMAC of the user ID, never the ID itself.
+	mac := hmac.New(sha256.New, []byte("audit-key"))
+	mac.Write([]byte("user1"))
+	subject := hex.EncodeToString(mac.Sum(nil))
+	mock.ExpectExec(`INSERT INTO user_erasures`).
+		WithArgs(subject, "ops").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Decisions made take two batches; the second is short, ending the step.
+	expectBatch := func(query string, deleted int64, column string) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).WithArgs("user1", 2).WillReturnResult(sqlmock.NewResult(0, deleted))
+		if deleted > 0 {
+			mock.ExpectExec(`UPDATE user_erasures SET `+column+` = `+column+` \+ \?`).
+				WithArgs(deleted, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+	}
+	expectBatch(`DELETE FROM decisions WHERE actor_user_id`, 2, "decisions_made_deleted")
+	expectBatch(`DELETE FROM decisions WHERE actor_user_id`, 1, "decisions_made_deleted")
+	expectBatch(`DELETE FROM decisions WHERE recipient_user_id`, 0, "")
+	expectBatch(`DELETE FROM decisions_archive WHERE actor_user_id`, 0, "")
+	expectBatch(`DELETE FROM decisions_archive WHERE recipient_user_id`, 0, "")
+	expectBatch(`DELETE FROM idempotency_keys`, 1, "derived_rows_deleted")
+	expectBatch(`DELETE FROM actor_activity`, 1, "derived_rows_deleted")
+	expectBatch(`DELETE FROM abuse_flags`, 0, "")
+	expectBatch(`DELETE FROM like_watermarks`, 1, "derived_rows_deleted")
+	expectBatch(`DELETE FROM seen_likes WHERE recipient_user_id`, 0, "")
+	expectBatch(`DELETE FROM seen_likes WHERE actor_user_id`, 1, "derived_rows_deleted")
+
+	completedAt := time.Unix(1700000000, 0)
+	mock.ExpectExec(`UPDATE user_erasures SET completed_at = NOW\(\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT decisions_made_deleted, decisions_received_deleted, derived_rows_deleted, completed_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "c", "d"}).AddRow(3, 0, 2, completedAt))
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ops", Service: true})
+	admin := service.NewAdminServer(service.NewExploreServer(db,
+		service.WithErasureBatchSize(2), service.WithErasureAuditKey("audit-key")))
+	res, err := admin.EraseUser(ctx, &pb.EraseUserRequest{UserId: "user1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GetDecisionsMadeDeleted() != 3 || res.GetDerivedRowsDeleted() != 2 ||
+		res.GetCompletedUnixTimestamp() != uint64(completedAt.Unix()) {
+		t.Errorf("unexpected response: %v", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestEraseUser_NoAuditKey verifies erasure is refused before touching the
+// database when subjects cannot be hashed with a secret.
+func TestEraseUser_NoAuditKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	admin := service.NewAdminServer(service.NewExploreServer(db))
+	_, err = admin.EraseUser(context.Background(), &pb.EraseUserRequest{UserId: "user1"})
+	if got := status.Code(err); got != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v (%v)", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
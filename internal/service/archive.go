@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// WithArchive moves decisions that have not changed for after into
+// decisions_archive, and makes PutDecision consult the archive for matches
+// and move archived pairs back before updating them.
+func WithArchive(after time.Duration) Option {
+	return func(s *ExploreServer) { s.archiveAfter = after }
+}
+
+// archivedReverse excludes likes in table whose reverse decision is archived
+// and matches cond, so a like answered long ago is not shown as new. It adds
+// nothing while archival is off.
+func (s *ExploreServer) archivedReverse(table, cond string) string {
+	if s.archiveAfter <= 0 {
+		return ""
+	}
+	return `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM decisions_archive ra
+			  WHERE ra.actor_user_id = ` + table + `.recipient_user_id AND ra.recipient_user_id = ` + table + `.actor_user_id` + cond + `
+		  )`
+}
+
+// ArchiveDecisions moves up to limit of the least recently changed decisions
+// older than the archive threshold into decisions_archive and reports how
+// many were moved. Each batch is one transaction holding locks on the moved
+// rows, so a concurrent PutDecision waits and then finds the pair archived.
+func (s *ExploreServer) ArchiveDecisions(ctx context.Context, limit int) (int64, error) {
+	if s.archiveAfter <= 0 {
+		return 0, nil
+	}
+	var n int64
+	err := s.observe(ctx, "archive_decisions", func(ctx context.Context) error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		// Ordering by updated_at alone walks idx_decisions_updated_at and
+		// locks only the rows it returns. Ties are moved in any order.
+		rows, err := tx.QueryContext(ctx, `
+			SELECT actor_user_id, recipient_user_id FROM decisions
+			WHERE updated_at < `+cutoff(s.archiveAfter)+`
+			ORDER BY updated_at
+			LIMIT ?
+			FOR UPDATE
+		`, limit)
+		if err != nil {
+			return err
+		}
+		var keys []any
+		for rows.Next() {
+			var actor, recipient string
+			if err := rows.Scan(&actor, &recipient); err != nil {
+				rows.Close()
+				return err
+			}
+			keys = append(keys, actor, recipient)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return tx.Commit()
+		}
+
+		pairs := pairList(len(keys) / 2)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO decisions_archive (id, actor_user_id, recipient_user_id, liked_recipient, created_at, updated_at)
+			SELECT id, actor_user_id, recipient_user_id, liked_recipient, created_at, updated_at
+			FROM decisions
+			WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)
+			ON DUPLICATE KEY UPDATE
+				id = VALUES(id),
+				liked_recipient = VALUES(liked_recipient),
+				created_at = VALUES(created_at),
+				updated_at = VALUES(updated_at),
+				archived_at = CURRENT_TIMESTAMP
+		`, keys...); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx,
+			`DELETE FROM decisions WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)`, keys...)
+		if err != nil {
+			return err
+		}
+		if n, err = res.RowsAffected(); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	return n, err
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// putUnarchived runs upsert in one transaction with moving the archived pairs
+// among keys (actor, recipient, actor, ...) back into decisions, keeping their
+// ids and timestamps, so the upsert updates them in place. Locks are taken in
+// the order ArchiveDecisions takes them, decisions first, so the two wait on
+// each other instead of deadlocking.
+func (s *ExploreServer) putUnarchived(ctx context.Context, keys []any, upsert func(context.Context, execer) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	pairs := pairList(len(keys) / 2)
+	err = s.observe(ctx, "unarchive_decision", func(ctx context.Context) error {
+		var hot int
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM decisions
+			WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)
+			FOR UPDATE
+		`, keys...).Scan(&hot)
+		if err != nil || hot == len(keys)/2 {
+			// Hot pairs are locked now and cannot be archived under us.
+			return err
+		}
+
+		var archived int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM decisions_archive
+			WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)
+			FOR UPDATE
+		`, keys...).Scan(&archived)
+		if err != nil || archived == 0 {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO decisions (id, actor_user_id, recipient_user_id, liked_recipient, created_at, updated_at)
+			SELECT id, actor_user_id, recipient_user_id, liked_recipient, created_at, updated_at
+			FROM decisions_archive
+			WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)
+		`, keys...); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`DELETE FROM decisions_archive WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)`, keys...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := upsert(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// pairList returns placeholders for n (actor, recipient) pairs.
+func pairList(n int) string {
+	return strings.TrimSuffix(strings.Repeat("(?, ?), ", n), ", ")
+}
@@ -0,0 +1,154 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// TestArchiveDecisions verifies a batch is locked, copied and deleted in one transaction.
+func TestArchiveDecisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE updated_at < NOW() - INTERVAL 31536000 SECOND
+			ORDER BY updated_at
+			LIMIT ?
+			FOR UPDATE`)).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"actor_user_id", "recipient_user_id"}).
+			AddRow("alice", "bob").
+			AddRow("carol", "bob"))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions_archive`)).
+		WithArgs("alice", "bob", "carol", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM decisions WHERE (actor_user_id, recipient_user_id) IN ((?, ?), (?, ?))`)).
+		WithArgs("alice", "bob", "carol", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	srv := service.NewExploreServer(db, service.WithArchive(365*24*time.Hour))
+	n, err := srv.ArchiveDecisions(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 decisions archived, got %d", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPutDecision_Archived verifies an archived pair is moved back in the
+// same transaction as the update and that an archived like still makes a
+// match.
+func TestPutDecision_Archived(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions WHERE (actor_user_id, recipient_user_id) IN ((?, ?)) FOR UPDATE`)).
+		WithArgs("alice", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions_archive WHERE (actor_user_id, recipient_user_id) IN ((?, ?)) FOR UPDATE`)).
+		WithArgs("alice", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions (id, actor_user_id`)).
+		WithArgs("alice", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM decisions_archive WHERE (actor_user_id, recipient_user_id) IN ((?, ?))`)).
+		WithArgs("alice", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions (actor_user_id, recipient_user_id, liked_recipient)`)).
+		WithArgs("alice", "bob", true).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions_archive`)).
+		WithArgs("bob", "alice", "bob", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	srv := service.NewExploreServer(db, service.WithArchive(365*24*time.Hour))
+	res, err := srv.PutDecision(context.Background(), &pb.PutDecisionRequest{
+		ActorUserId:     "alice",
+		RecipientUserId: "bob",
+		LikedRecipient:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.GetMutualLikes() {
+		t.Error("expected a match with the archived like")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPutDecision_ArchiveHot verifies a pair still in decisions is locked
+// there and the archive is left alone.
+func TestPutDecision_ArchiveHot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions WHERE (actor_user_id, recipient_user_id) IN ((?, ?)) FOR UPDATE`)).
+		WithArgs("alice", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions (actor_user_id, recipient_user_id, liked_recipient)`)).
+		WithArgs("alice", "bob", false).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	srv := service.NewExploreServer(db, service.WithArchive(365*24*time.Hour))
+	_, err = srv.PutDecision(context.Background(), &pb.PutDecisionRequest{
+		ActorUserId:     "alice",
+		RecipientUserId: "bob",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestListNewLikedYou_Archived verifies likes answered by an archived like are not new.
+func TestListNewLikedYou_Archived(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+			  SELECT 1 FROM decisions_archive ra
+			  WHERE ra.actor_user_id = d.recipient_user_id AND ra.recipient_user_id = d.actor_user_id AND ra.liked_recipient = TRUE
+		  )`)).
+		WithArgs("bob", "bob", service.DefaultLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}))
+
+	srv := service.NewExploreServer(db, service.WithArchive(365*24*time.Hour))
+	if _, err := srv.ListNewLikedYou(context.Background(), &pb.ListLikedYouRequest{RecipientUserId: "bob"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	pb "github.com/KEdore/explore/proto"
+)
+
+// OwnerOf reports which user an ExploreService request acts for: the actor
+// for PutDecision and the recipient for the list, count and seen RPCs.
+func OwnerOf(req any) (userID, field string, ok bool) {
+	switch r := req.(type) {
+	case *pb.PutDecisionRequest:
+		return r.GetActorUserId(), "actor_user_id", true
+	case *pb.ListLikedYouRequest:
+		return r.GetRecipientUserId(), "recipient_user_id", true
+	case *pb.CountLikedYouRequest:
+		return r.GetRecipientUserId(), "recipient_user_id", true
+	case *pb.MarkLikesSeenRequest:
+		return r.GetRecipientUserId(), "recipient_user_id", true
+	}
+	return "", "", false
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/KEdore/explore/internal/apperr"
+	"github.com/KEdore/explore/internal/auth"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// DefaultErasureBatchSize bounds how many rows each erasure DELETE removes,
+// keeping lock times short on large histories.
+const DefaultErasureBatchSize = 1000
+
+// WithErasureBatchSize overrides DefaultErasureBatchSize.
+func WithErasureBatchSize(n int) Option {
+	return func(s *ExploreServer) { s.erasureBatchSize = n }
+}
+
+// WithErasureAuditKey sets the secret that keys subject hashes in the erasure
+// audit trail. EraseUser is refused until one is set.
+func WithErasureAuditKey(key string) Option {
+	return func(s *ExploreServer) { s.erasureAuditKey = []byte(key) }
+}
+
+// erasureStep deletes one kind of row for a user, in batches.
+type erasureStep struct {
+	operation string
+	query     string
+	// column is the user_erasures counter the deleted rows are added to.
+	column string
+}
+
+// erasureSteps lists everything stored about a user. Each query deletes at
+// most one batch and is repeated until nothing is left.
+var erasureSteps = []erasureStep{
+	{"erase_decisions_made", `DELETE FROM decisions WHERE actor_user_id = ? LIMIT ?`, "decisions_made_deleted"},
+	{"erase_decisions_received", `DELETE FROM decisions WHERE recipient_user_id = ? LIMIT ?`, "decisions_received_deleted"},
+	{"erase_archived_decisions_made", `DELETE FROM decisions_archive WHERE actor_user_id = ? LIMIT ?`, "decisions_made_deleted"},
+	{"erase_archived_decisions_received", `DELETE FROM decisions_archive WHERE recipient_user_id = ? LIMIT ?`, "decisions_received_deleted"},
+	{"erase_idempotency_keys", `DELETE FROM idempotency_keys WHERE actor_user_id = ? LIMIT ?`, "derived_rows_deleted"},
+	{"erase_actor_activity", `DELETE FROM actor_activity WHERE actor_user_id = ? LIMIT ?`, "derived_rows_deleted"},
+	{"erase_abuse_flags", `DELETE FROM abuse_flags WHERE actor_user_id = ? LIMIT ?`, "derived_rows_deleted"},
+	{"erase_like_watermarks", `DELETE FROM like_watermarks WHERE recipient_user_id = ? LIMIT ?`, "derived_rows_deleted"},
+	{"erase_seen_likes_received", `DELETE FROM seen_likes WHERE recipient_user_id = ? LIMIT ?`, "derived_rows_deleted"},
+	{"erase_seen_likes_made", `DELETE FROM seen_likes WHERE actor_user_id = ? LIMIT ?`, "derived_rows_deleted"},
+}
+
+// subjectHash identifies an erased user in the audit trail without keeping
+// their ID. It is keyed so the ID cannot be recovered by hashing candidates.
+func subjectHash(key []byte, userID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// EraseUser deletes every decision the user made or received and the rows
+// derived from them. Deletes run in bounded batches and progress is recorded
+// after each one, so an interrupted erasure is finished by calling again.
+func (a *AdminServer) EraseUser(ctx context.Context, req *pb.EraseUserRequest) (*pb.EraseUserResponse, error) {
+	if len(a.s.erasureAuditKey) == 0 {
+		return nil, apperr.New(codes.FailedPrecondition, apperr.ReasonPreconditionError, "erasure audit key is not configured")
+	}
+	userID := req.GetUserId()
+	subject := subjectHash(a.s.erasureAuditKey, userID)
+	requestedBy := ""
+	if p, ok := auth.FromContext(ctx); ok {
+		requestedBy = p.Subject
+	}
+
+	startQuery := `
+		INSERT INTO user_erasures (subject_hash, requested_by)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE
+			attempts = attempts + 1,
+			requested_by = VALUES(requested_by)
+	`
+	err := a.s.observe(ctx, "start_user_erasure", func(ctx context.Context) error {
+		_, err := a.s.db.ExecContext(ctx, startQuery, subject, requestedBy)
+		return err
+	})
+	if err != nil {
+		return nil, apperr.FromDB(err, "failed to record user erasure")
+	}
+
+	batch := a.s.erasureBatchSize
+	if batch <= 0 {
+		batch = DefaultErasureBatchSize
+	}
+	for _, step := range erasureSteps {
+		for {
+			n, err := a.eraseBatch(ctx, step, userID, subject, batch)
+			if err != nil {
+				return nil, apperr.FromDB(err, "failed to erase user data")
+			}
+			if n < int64(batch) {
+				break
+			}
+		}
+	}
+
+	completeQuery := `UPDATE user_erasures SET completed_at = NOW() WHERE subject_hash = ?`
+	err = a.s.observe(ctx, "complete_user_erasure", func(ctx context.Context) error {
+		_, err := a.s.db.ExecContext(ctx, completeQuery, subject)
+		return err
+	})
+	if err != nil {
+		return nil, apperr.FromDB(err, "failed to complete user erasure")
+	}
+
+	auditQuery := `
+		SELECT decisions_made_deleted, decisions_received_deleted, derived_rows_deleted, completed_at
+		FROM user_erasures WHERE subject_hash = ?
+	`
+	res := &pb.EraseUserResponse{}
+	var completedAt time.Time
+	err = a.s.observe(ctx, "get_user_erasure", func(ctx context.Context) error {
+		return a.s.db.QueryRowContext(ctx, auditQuery, subject).Scan(
+			&res.DecisionsMadeDeleted, &res.DecisionsReceivedDeleted, &res.DerivedRowsDeleted, &completedAt)
+	})
+	if err != nil {
+		return nil, apperr.FromDB(err, "failed to read user erasure")
+	}
+	res.CompletedUnixTimestamp = uint64(completedAt.Unix())
+	return res, nil
+}
+
+// eraseBatch deletes one batch for step and adds the deleted rows to the
+// audit record in the same transaction, so the counts stay exact across retries.
+func (a *AdminServer) eraseBatch(ctx context.Context, step erasureStep, userID, subject string, batch int) (int64, error) {
+	var n int64
+	err := a.s.observe(ctx, step.operation, func(ctx context.Context) error {
+		tx, err := a.s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		res, err := tx.ExecContext(ctx, step.query, userID, batch)
+		if err != nil {
+			return err
+		}
+		if n, err = res.RowsAffected(); err != nil {
+			return err
+		}
+		if n > 0 {
+			// step.column comes from erasureSteps, never from the request.
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE user_erasures SET `+step.column+` = `+step.column+` + ? WHERE subject_hash = ?`,
+				n, subject); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+	return n, err
+}
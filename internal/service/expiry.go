@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// ExpiryPolicy hides decisions once they are older than their TTL, measured
+// from updated_at. A zero TTL keeps decisions of that kind forever.
+type ExpiryPolicy struct {
+	// PassTTL lets a passed profile reappear, e.g. after 90 days.
+	PassTTL time.Duration
+	// LikeTTL hides old likes from lists, counts and match checks.
+	LikeTTL time.Duration
+}
+
+// WithExpiry applies p to every query, before the reaper has deleted the
+// expired rows.
+func WithExpiry(p ExpiryPolicy) Option {
+	return func(s *ExploreServer) { s.expiry = p }
+}
+
+// cutoff is the SQL time before which decisions with ttl have expired. The
+// TTL comes from configuration, never from a request, so it is inlined.
+func cutoff(ttl time.Duration) string {
+	return "NOW() - INTERVAL " + strconv.FormatInt(int64(ttl/time.Second), 10) + " SECOND"
+}
+
+// liveCondition keeps rows in table updated within ttl.
+func liveCondition(table string, ttl time.Duration) string {
+	if ttl <= 0 {
+		return ""
+	}
+	return " AND " + table + ".updated_at >= " + cutoff(ttl)
+}
+
+// liveLike keeps unexpired likes in table; table must already be limited to likes.
+func (s *ExploreServer) liveLike(table string) string {
+	return liveCondition(table, s.expiry.LikeTTL)
+}
+
+// liveDecision keeps unexpired decisions of either kind in table.
+func (s *ExploreServer) liveDecision(table string) string {
+	if s.expiry.LikeTTL <= 0 && s.expiry.PassTTL <= 0 {
+		return ""
+	}
+	return " AND ((" + table + ".liked_recipient = TRUE" + liveCondition(table, s.expiry.LikeTTL) +
+		") OR (" + table + ".liked_recipient = FALSE" + liveCondition(table, s.expiry.PassTTL) + "))"
+}
+
+// refreshClause renews updated_at when PutDecision changes a decision or
+// repeats one that has expired, so the repeated decision counts from now.
+// Setting updated_at explicitly turns off its ON UPDATE default, hence the
+// change check. It must precede the liked_recipient assignment because MySQL
+// applies assignments left to right.
+func (s *ExploreServer) refreshClause() string {
+	if s.expiry.LikeTTL <= 0 && s.expiry.PassTTL <= 0 {
+		return ""
+	}
+	renew := "liked_recipient <> VALUES(liked_recipient)"
+	if s.expiry.LikeTTL > 0 {
+		renew += " OR (liked_recipient = TRUE AND updated_at < " + cutoff(s.expiry.LikeTTL) + ")"
+	}
+	if s.expiry.PassTTL > 0 {
+		renew += " OR (liked_recipient = FALSE AND updated_at < " + cutoff(s.expiry.PassTTL) + ")"
+	}
+	return "updated_at = IF(" + renew + ", CURRENT_TIMESTAMP, updated_at),\n\t\t\t"
+}
+
+// PurgeExpiredDecisions deletes up to limit expired passes and likes, live
+// or archived, and reports how many were removed. Passes go first; later
+// steps fill what is left of the batch.
+func (s *ExploreServer) PurgeExpiredDecisions(ctx context.Context, limit int) (int64, error) {
+	var total int64
+	for _, step := range []struct {
+		operation string
+		table     string
+		liked     bool
+		ttl       time.Duration
+	}{
+		{"purge_expired_passes", "decisions", false, s.expiry.PassTTL},
+		{"purge_expired_archived_passes", "decisions_archive", false, s.expiry.PassTTL},
+		{"purge_expired_likes", "decisions", true, s.expiry.LikeTTL},
+		{"purge_expired_archived_likes", "decisions_archive", true, s.expiry.LikeTTL},
+	} {
+		if step.ttl <= 0 || total >= int64(limit) {
+			continue
+		}
+		err := s.observe(ctx, step.operation, func(ctx context.Context) error {
+			n, err := s.purgeExpired(ctx, step.table, step.liked, step.ttl, limit-int(total))
+			total += n
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// purgeExpired deletes up to limit expired decisions from table together
+// with their seen_likes rows, which would otherwise be orphaned and still
+// exported. table comes from PurgeExpiredDecisions, never from a request.
+func (s *ExploreServer) purgeExpired(ctx context.Context, table string, liked bool, ttl time.Duration, limit int) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT actor_user_id, recipient_user_id FROM `+table+`
+		WHERE liked_recipient = ? AND updated_at < NOW() - INTERVAL ? SECOND
+		LIMIT ?
+		FOR UPDATE
+	`, liked, int64(ttl/time.Second), limit)
+	if err != nil {
+		return 0, err
+	}
+	var keys []any
+	for rows.Next() {
+		var actor, recipient string
+		if err := rows.Scan(&actor, &recipient); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		keys = append(keys, actor, recipient)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, tx.Commit()
+	}
+
+	pairs := pairList(len(keys) / 2)
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM seen_likes WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)`, keys...); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx,
+		`DELETE FROM `+table+` WHERE (actor_user_id, recipient_user_id) IN (`+pairs+`)`, keys...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
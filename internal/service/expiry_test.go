@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+var testExpiry = service.ExpiryPolicy{PassTTL: 90 * 24 * time.Hour, LikeTTL: 365 * 24 * time.Hour}
+
+// TestListNewLikedYou_Expiry verifies expired likes are hidden before the reaper runs.
+func TestListNewLikedYou_Expiry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		WHERE d.recipient_user_id = ? AND d.liked_recipient = TRUE AND d.updated_at >= NOW() - INTERVAL 31536000 SECOND
+		  AND NOT EXISTS (
+			  SELECT 1 FROM decisions rd
+			  WHERE rd.actor_user_id = d.recipient_user_id AND rd.recipient_user_id = d.actor_user_id AND ((rd.liked_recipient = TRUE AND rd.updated_at >= NOW() - INTERVAL 31536000 SECOND) OR (rd.liked_recipient = FALSE AND rd.updated_at >= NOW() - INTERVAL 7776000 SECOND))
+		  )
+		  AND NOT EXISTS (
+			  SELECT 1 FROM decisions d2
+			  WHERE d2.actor_user_id = ? AND d2.recipient_user_id = d.actor_user_id AND d2.liked_recipient = TRUE AND d2.updated_at >= NOW() - INTERVAL 31536000 SECOND
+		  )`)).
+		WithArgs("bob", "bob", service.DefaultLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}))
+
+	undecided := true
+	srv := service.NewExploreServer(db, service.WithExpiry(testExpiry))
+	if _, err := srv.ListNewLikedYou(context.Background(), &pb.ListLikedYouRequest{
+		RecipientUserId: "bob",
+		UndecidedOnly:   &undecided,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPutDecision_RenewsExpired verifies a repeated decision is renewed once
+// the stored one has expired, and that expired likes do not match.
+func TestPutDecision_RenewsExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`
+		ON DUPLICATE KEY UPDATE
+			updated_at = IF(liked_recipient <> VALUES(liked_recipient) OR (liked_recipient = TRUE AND updated_at < NOW() - INTERVAL 31536000 SECOND) OR (liked_recipient = FALSE AND updated_at < NOW() - INTERVAL 7776000 SECOND), CURRENT_TIMESTAMP, updated_at),
+			liked_recipient = VALUES(liked_recipient)`)).
+		WithArgs("alice", "bob", true).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(regexp.QuoteMeta(`liked_recipient = TRUE AND decisions.updated_at >= NOW() - INTERVAL 31536000 SECOND`)).
+		WithArgs("bob", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+
+	srv := service.NewExploreServer(db, service.WithExpiry(testExpiry))
+	res, err := srv.PutDecision(context.Background(), &pb.PutDecisionRequest{
+		ActorUserId:     "alice",
+		RecipientUserId: "bob",
+		LikedRecipient:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GetMutualLikes() {
+		t.Error("expected no match")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPurgeExpiredDecisions verifies passes are purged first, likes fill
+// the rest of the batch and seen_likes rows go with their decisions.
+func TestPurgeExpiredDecisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	// expectPurge expects one batch from table returning the given pairs.
+	expectPurge := func(table string, liked bool, ttl int64, limit int, pairs ...string) {
+		rows := sqlmock.NewRows([]string{"actor_user_id", "recipient_user_id"})
+		var keys []driver.Value
+		for i := 0; i < len(pairs); i += 2 {
+			rows.AddRow(pairs[i], pairs[i+1])
+			keys = append(keys, pairs[i], pairs[i+1])
+		}
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT actor_user_id, recipient_user_id FROM `+table+` WHERE liked_recipient = ? AND updated_at < NOW() - INTERVAL ? SECOND LIMIT ? FOR UPDATE`)).
+			WithArgs(liked, ttl, limit).
+			WillReturnRows(rows)
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM seen_likes WHERE (actor_user_id, recipient_user_id) IN (`)).
+			WithArgs(keys...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM ` + table + ` WHERE (actor_user_id, recipient_user_id) IN (`)).
+			WithArgs(keys...).
+			WillReturnResult(sqlmock.NewResult(0, int64(len(keys)/2)))
+		mock.ExpectCommit()
+	}
+	expectPurge("decisions", false, 7776000, 4, "a", "b", "a", "c")
+	expectPurge("decisions_archive", false, 7776000, 2, "b", "c")
+	expectPurge("decisions", true, 31536000, 1, "c", "a")
+
+	srv := service.NewExploreServer(db, service.WithExpiry(testExpiry))
+	n, err := srv.PurgeExpiredDecisions(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("expected 4 rows purged, got %d", n)
+	}
+
+	// Without a policy nothing is deleted.
+	n, err = service.NewExploreServer(db).PurgeExpiredDecisions(context.Background(), 4)
+	if err != nil || n != 0 {
+		t.Errorf("expected no purge, got %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
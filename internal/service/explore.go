@@ -5,7 +5,15 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/KEdore/explore/internal/abuse"
+	"github.com/KEdore/explore/internal/apperr"
+	"github.com/KEdore/explore/internal/metrics"
+	"github.com/KEdore/explore/internal/tracing"
 	pb "github.com/KEdore/explore/proto"
 )
 
@@ -13,31 +21,122 @@ const DefaultLimit = 20
 
 type ExploreServer struct {
 	pb.UnimplementedExploreServiceServer
-	db *sql.DB
+	db      *sql.DB
+	metrics *metrics.Metrics
+	tracer  trace.TracerProvider
+
+	idempotencyTTL time.Duration
+	abusePolicy    *abuse.Policy
+	expiry         ExpiryPolicy
+	archiveAfter   time.Duration
+
+	erasureBatchSize int
+	erasureAuditKey  []byte
+}
+
+// Option customizes an ExploreServer.
+type Option func(*ExploreServer)
+
+// WithMetrics records query latency and domain counters in m.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *ExploreServer) { s.metrics = m }
 }
 
-func NewExploreServer(db *sql.DB) *ExploreServer {
-	return &ExploreServer{db: db}
+// WithTracerProvider creates query spans with tp instead of the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *ExploreServer) { s.tracer = tp }
+}
+
+func NewExploreServer(db *sql.DB, opts ...Option) *ExploreServer {
+	s := &ExploreServer{db: db}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// observe runs a storage operation in its own span and records its latency
+// under the operation name.
+func (s *ExploreServer) observe(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	ctx, span := tracing.StartQuery(ctx, s.tracer, operation)
+	start := time.Now()
+	err := fn(ctx)
+	s.metrics.ObserveQuery(operation, time.Since(start), err)
+	tracing.EndQuery(span, err)
+	return err
 }
 
 // PutDecision inserts (or updates) a decision without any timestamp logic.
+// Requests carrying an idempotency key are executed at most once per key.
 func (s *ExploreServer) PutDecision(ctx context.Context, req *pb.PutDecisionRequest) (*pb.PutDecisionResponse, error) {
+	key := idempotencyKey(ctx, req)
+	if key == "" || s.idempotencyTTL <= 0 {
+		return s.putDecision(ctx, req)
+	}
+	// Keys sent as metadata bypass the request validator.
+	if len(key) > MaxIdempotencyKeyLen {
+		return nil, apperr.InvalidArgument(apperr.ReasonInvalidArgument, "idempotency key is too long").
+			With("field", "idempotency_key")
+	}
+
+	actor := req.GetActorUserId()
+	hash := decisionHash(req)
+	owned, replay, err := s.reserveIdempotencyKey(ctx, actor, key, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return replay, nil
+	}
+
+	res, err := s.putDecision(ctx, req)
+	if err != nil {
+		s.releaseIdempotencyKey(ctx, actor, key, hash)
+		return nil, err
+	}
+	if err := s.completeIdempotencyKey(ctx, actor, key, hash, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *ExploreServer) putDecision(ctx context.Context, req *pb.PutDecisionRequest) (*pb.PutDecisionResponse, error) {
 	query := `
 		INSERT INTO decisions (actor_user_id, recipient_user_id, liked_recipient)
 		VALUES (?, ?, ?)
 		ON DUPLICATE KEY UPDATE
-			liked_recipient = VALUES(liked_recipient)
+			` + s.refreshClause() + `liked_recipient = VALUES(liked_recipient)
 	`
 
-	_, err := s.db.ExecContext(
-		ctx,
-		query,
-		req.GetActorUserId(),
-		req.GetRecipientUserId(),
-		req.GetLikedRecipient(),
-	)
+	// Without CLIENT_FOUND_ROWS, MySQL reports 0 affected rows when the
+	// upsert leaves the stored decision as it was.
+	var changed bool
+	upsert := func(ctx context.Context, db execer) error {
+		return s.observe(ctx, "put_decision", func(ctx context.Context) error {
+			res, err := db.ExecContext(
+				ctx,
+				query,
+				req.GetActorUserId(),
+				req.GetRecipientUserId(),
+				req.GetLikedRecipient(),
+			)
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			changed = n > 0
+			return err
+		})
+	}
+
+	var err error
+	if s.archiveAfter > 0 {
+		err = s.putUnarchived(ctx, []any{req.GetActorUserId(), req.GetRecipientUserId()}, upsert)
+	} else {
+		err = upsert(ctx, s.db)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to put decision: %w", err)
+		return nil, apperr.FromDB(err, "failed to put decision")
 	}
 
 	// Check for mutual like.
@@ -45,69 +144,71 @@ func (s *ExploreServer) PutDecision(ctx context.Context, req *pb.PutDecisionRequ
 	if req.GetLikedRecipient() {
 		mutualQuery := `
 			SELECT COUNT(*) FROM decisions
-			WHERE actor_user_id = ? AND recipient_user_id = ? AND liked_recipient = TRUE
+			WHERE actor_user_id = ? AND recipient_user_id = ? AND liked_recipient = TRUE` + s.liveLike("decisions") + `
 		`
+		args := []any{req.GetRecipientUserId(), req.GetActorUserId()}
+		if s.archiveAfter > 0 {
+			// The other user's like may have gone cold.
+			mutualQuery = `
+			SELECT
+				(SELECT COUNT(*) FROM decisions
+				 WHERE actor_user_id = ? AND recipient_user_id = ? AND liked_recipient = TRUE` + s.liveLike("decisions") + `) +
+				(SELECT COUNT(*) FROM decisions_archive
+				 WHERE actor_user_id = ? AND recipient_user_id = ? AND liked_recipient = TRUE` + s.liveLike("decisions_archive") + `)
+			`
+			args = append(args, args...)
+		}
 		var count int
-		err = s.db.QueryRowContext(ctx, mutualQuery, req.GetRecipientUserId(), req.GetActorUserId()).Scan(&count)
+		err = s.observe(ctx, "check_mutual_like", func(ctx context.Context) error {
+			return s.db.QueryRowContext(ctx, mutualQuery, args...).Scan(&count)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to check mutual like: %w", err)
+			return nil, apperr.FromDB(err, "failed to check mutual like")
 		}
 		if count > 0 {
 			mutual = true
 		}
 	}
 
+	// Re-sending a decision that is already stored changes nothing.
+	if changed {
+		s.metrics.RecordDecision(req.GetLikedRecipient(), mutual)
+		s.recordActivity(ctx, req, mutual)
+	}
+
 	return &pb.PutDecisionResponse{
 		MutualLikes: mutual,
 	}, nil
 }
 
-// ListLikedYou returns a list of users who liked the recipient.
+// ListLikedYou returns a list of users who liked the recipient. Likes from
+// flagged actors are hidden.
 func (s *ExploreServer) ListLikedYou(ctx context.Context, req *pb.ListLikedYouRequest) (*pb.ListLikedYouResponse, error) {
-	offset := 0
-	if token := req.GetPaginationToken(); token != "" {
-		var err error
-		offset, err = strconv.Atoi(token)
-		if err != nil {
-			return nil, fmt.Errorf("invalid pagination token: %w", err)
-		}
+	filter, filterArgs, err := s.likePage("decisions", req)
+	if err != nil {
+		return nil, err
 	}
-
 	query := `
-		SELECT actor_user_id
+		SELECT id, actor_user_id, UNIX_TIMESTAMP(updated_at)
 		FROM decisions
-		WHERE recipient_user_id = ? AND liked_recipient = TRUE
-		ORDER BY id DESC
-		LIMIT ? OFFSET ?
+		WHERE recipient_user_id = ? AND liked_recipient = TRUE` + filter + `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM abuse_flags f
+			  WHERE f.actor_user_id = decisions.actor_user_id AND f.cleared_at IS NULL
+		  )
+		ORDER BY updated_at DESC, id DESC
+		LIMIT ?
 	`
-	rows, err := s.db.QueryContext(ctx, query, req.GetRecipientUserId(), DefaultLimit, offset)
+	args := append([]any{req.GetRecipientUserId()}, filterArgs...)
+	likers, lastID, err := s.queryLikers(ctx, "list_liked_you", query, append(args, DefaultLimit)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query liked decisions: %w", err)
-	}
-	defer rows.Close()
-
-	var likers []*pb.ListLikedYouResponse_Liker
-	count := 0
-	for rows.Next() {
-		var actorID string
-		// Since there is no timestamp column, we set ts to 0.
-		ts := int64(0)
-		if err := rows.Scan(&actorID); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		likers = append(likers, &pb.ListLikedYouResponse_Liker{
-			ActorId:       actorID,
-			UnixTimestamp: uint64(ts),
-		})
-		count++
-	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return nil, apperr.FromDB(err, "failed to query liked decisions")
 	}
+	count := len(likers)
 
 	nextToken := ""
 	if count == DefaultLimit {
-		nextToken = strconv.Itoa(offset + DefaultLimit)
+		nextToken = fmt.Sprintf("%d:%d", likers[count-1].GetUnixTimestamp(), lastID)
 	}
 
 	return &pb.ListLikedYouResponse{
@@ -118,53 +219,36 @@ func (s *ExploreServer) ListLikedYou(ctx context.Context, req *pb.ListLikedYouRe
 
 // ListNewLikedYou returns users who liked the recipient excluding those who have already liked back.
 func (s *ExploreServer) ListNewLikedYou(ctx context.Context, req *pb.ListLikedYouRequest) (*pb.ListLikedYouResponse, error) {
-	offset := 0
-	if token := req.GetPaginationToken(); token != "" {
-		var err error
-		offset, err = strconv.Atoi(token)
-		if err != nil {
-			return nil, fmt.Errorf("invalid pagination token: %w", err)
-		}
+	filter, filterArgs, err := s.likePage("d", req)
+	if err != nil {
+		return nil, err
 	}
-
 	query := `
-		SELECT d.actor_user_id
+		SELECT d.id, d.actor_user_id, UNIX_TIMESTAMP(d.updated_at)
 		FROM decisions d
-		WHERE d.recipient_user_id = ? AND d.liked_recipient = TRUE
+		WHERE d.recipient_user_id = ? AND d.liked_recipient = TRUE` + filter + `
 		  AND NOT EXISTS (
 			  SELECT 1 FROM decisions d2
-			  WHERE d2.actor_user_id = ? AND d2.recipient_user_id = d.actor_user_id AND d2.liked_recipient = TRUE
+			  WHERE d2.actor_user_id = ? AND d2.recipient_user_id = d.actor_user_id AND d2.liked_recipient = TRUE` + s.liveLike("d2") + `
+		  )` + s.archivedReverse("d", " AND ra.liked_recipient = TRUE"+s.liveLike("ra")) + `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM abuse_flags f
+			  WHERE f.actor_user_id = d.actor_user_id AND f.cleared_at IS NULL
 		  )
-		ORDER BY d.id DESC
-		LIMIT ? OFFSET ?
+		ORDER BY d.updated_at DESC, d.id DESC
+		LIMIT ?
 	`
-	rows, err := s.db.QueryContext(ctx, query, req.GetRecipientUserId(), req.GetRecipientUserId(), DefaultLimit, offset)
+	args := append([]any{req.GetRecipientUserId()}, filterArgs...)
+	args = append(args, req.GetRecipientUserId(), DefaultLimit)
+	likers, lastID, err := s.queryLikers(ctx, "list_new_liked_you", query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query new liked decisions: %w", err)
-	}
-	defer rows.Close()
-
-	var likers []*pb.ListLikedYouResponse_Liker
-	count := 0
-	for rows.Next() {
-		var actorID string
-		ts := int64(0)
-		if err := rows.Scan(&actorID); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		likers = append(likers, &pb.ListLikedYouResponse_Liker{
-			ActorId:       actorID,
-			UnixTimestamp: uint64(ts),
-		})
-		count++
-	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return nil, apperr.FromDB(err, "failed to query new liked decisions")
 	}
+	count := len(likers)
 
 	nextToken := ""
 	if count == DefaultLimit {
-		nextToken = strconv.Itoa(offset + DefaultLimit)
+		nextToken = fmt.Sprintf("%d:%d", likers[count-1].GetUnixTimestamp(), lastID)
 	}
 
 	return &pb.ListLikedYouResponse{
@@ -173,17 +257,141 @@ func (s *ExploreServer) ListNewLikedYou(ctx context.Context, req *pb.ListLikedYo
 	}, nil
 }
 
+// likePage returns likeFilters plus the keyset condition for the request's
+// pagination token, which is the like time and id of the last like on the
+// previous page. Likes are listed newest first by like time, so imported
+// history sorts by when it happened rather than when it was loaded, and the
+// id breaks ties within a second.
+func (s *ExploreServer) likePage(table string, req *pb.ListLikedYouRequest) (string, []any, error) {
+	filter, args := s.likeFilters(table, req)
+	if token := req.GetPaginationToken(); token != "" {
+		ts, id, err := parseLikeCursor(token)
+		if err != nil {
+			return "", nil, apperr.InvalidArgument(apperr.ReasonInvalidPageToken, "invalid pagination token").
+				With("field", "pagination_token").Wrap(err)
+		}
+		filter += " AND (" + table + ".updated_at < FROM_UNIXTIME(?) OR (" +
+			table + ".updated_at = FROM_UNIXTIME(?) AND " + table + ".id < ?))"
+		args = append(args, ts, ts, id)
+	}
+	return filter, args, nil
+}
+
+// parseLikeCursor decodes a "<unix time>:<id>" pagination token.
+func parseLikeCursor(token string) (uint64, uint64, error) {
+	tsPart, idPart, ok := strings.Cut(token, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("missing id in token %q", token)
+	}
+	ts, err := strconv.ParseUint(tsPart, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ts, id, nil
+}
+
+// likeFilters returns extra WHERE conditions on table for the expiry policy
+// and the optional request filters, with their arguments. updated_at is when
+// the decision last changed, which for a like is when it was made.
+func (s *ExploreServer) likeFilters(table string, req *pb.ListLikedYouRequest) (string, []any) {
+	filter := s.liveLike(table)
+	var args []any
+	if req.SinceUnixTimestamp != nil {
+		filter += " AND " + table + ".updated_at >= FROM_UNIXTIME(?)"
+		args = append(args, req.GetSinceUnixTimestamp())
+	}
+	if req.UntilUnixTimestamp != nil {
+		filter += " AND " + table + ".updated_at < FROM_UNIXTIME(?)"
+		args = append(args, req.GetUntilUnixTimestamp())
+	}
+	if req.GetUnseenOnly() {
+		filter += unseenCondition(table)
+	}
+	if req.GetUndecidedOnly() {
+		filter += s.undecidedCondition(table)
+	}
+	return filter, args
+}
+
+// undecidedCondition restricts likes in table to actors the recipient has not
+// decided on either way. It uses the primary key of the reverse decision.
+func (s *ExploreServer) undecidedCondition(table string) string {
+	return `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM decisions rd
+			  WHERE rd.actor_user_id = ` + table + `.recipient_user_id AND rd.recipient_user_id = ` + table + `.actor_user_id` + s.liveDecision("rd") + `
+		  )` + s.archivedReverse(table, s.liveDecision("ra"))
+}
+
+// parseOffset decodes a pagination token; an empty token starts at the beginning.
+func parseOffset(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(token)
+	if err != nil || offset < 0 {
+		return 0, apperr.InvalidArgument(apperr.ReasonInvalidPageToken, "invalid pagination token").
+			With("field", "pagination_token").Wrap(err)
+	}
+	return offset, nil
+}
+
+// queryLikers runs a query selecting the decision id, actor_user_id and the
+// like's Unix time, and collects the likers and the last id.
+func (s *ExploreServer) queryLikers(ctx context.Context, operation, query string, args ...any) ([]*pb.ListLikedYouResponse_Liker, uint64, error) {
+	var likers []*pb.ListLikedYouResponse_Liker
+	var lastID uint64
+	err := s.observe(ctx, operation, func(ctx context.Context) error {
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var actorID string
+			var ts int64
+			if err := rows.Scan(&lastID, &actorID, &ts); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			likers = append(likers, &pb.ListLikedYouResponse_Liker{
+				ActorId:       actorID,
+				UnixTimestamp: uint64(ts),
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows iteration error: %w", err)
+		}
+		return nil
+	})
+	return likers, lastID, err
+}
+
 // CountLikedYou returns the count of users who liked the recipient.
 func (s *ExploreServer) CountLikedYou(ctx context.Context, req *pb.CountLikedYouRequest) (*pb.CountLikedYouResponse, error) {
+	filter := s.liveLike("decisions")
+	if req.GetUndecidedOnly() {
+		filter += s.undecidedCondition("decisions")
+	}
 	query := `
 		SELECT COUNT(*)
 		FROM decisions
-		WHERE recipient_user_id = ? AND liked_recipient = TRUE
+		WHERE recipient_user_id = ? AND liked_recipient = TRUE` + filter + `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM abuse_flags f
+			  WHERE f.actor_user_id = decisions.actor_user_id AND f.cleared_at IS NULL
+		  )
 	`
 	var count int
-	err := s.db.QueryRowContext(ctx, query, req.GetRecipientUserId()).Scan(&count)
+	err := s.observe(ctx, "count_liked_you", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, query, req.GetRecipientUserId()).Scan(&count)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to count liked decisions: %w", err)
+		return nil, apperr.FromDB(err, "failed to count liked decisions")
 	}
 	return &pb.CountLikedYouResponse{
 		Count: uint64(count),
@@ -2,12 +2,22 @@ package service_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/KEdore/explore/internal/metrics"
 	"github.com/KEdore/explore/internal/service"
+	"github.com/KEdore/explore/internal/tracing"
 	pb "github.com/KEdore/explore/proto"
 )
 
@@ -164,17 +174,21 @@ func TestListLikedYou(t *testing.T) {
 	ctx := context.Background()
 
 	// Create rows to simulate two likers.
-	rows := sqlmock.NewRows([]string{"actor_user_id"}).
-		AddRow("actor1").
-		AddRow("actor2")
+	rows := sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}).
+		AddRow(12, "actor1", 1700000100).
+		AddRow(9, "actor2", 1700000000)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT actor_user_id
+		SELECT id, actor_user_id, UNIX_TIMESTAMP(updated_at)
 		FROM decisions
 		WHERE recipient_user_id = ? AND liked_recipient = TRUE
-		ORDER BY id DESC
-		LIMIT ? OFFSET ?
+		  AND NOT EXISTS (
+			  SELECT 1 FROM abuse_flags f
+			  WHERE f.actor_user_id = decisions.actor_user_id AND f.cleared_at IS NULL
+		  )
+		ORDER BY updated_at DESC, id DESC
+		LIMIT ?
 	`)).
-		WithArgs("recipient1", service.DefaultLimit, 0).
+		WithArgs("recipient1", service.DefaultLimit).
 		WillReturnRows(rows)
 
 	req := &pb.ListLikedYouRequest{
@@ -186,7 +200,10 @@ func TestListLikedYou(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 	if len(res.Likers) != 2 {
-		t.Errorf("expected 2 likers, got %d", len(res.Likers))
+		t.Fatalf("expected 2 likers, got %d", len(res.Likers))
+	}
+	if res.Likers[0].UnixTimestamp != 1700000100 {
+		t.Errorf("expected the like's timestamp, got %d", res.Likers[0].UnixTimestamp)
 	}
 	// If fewer than DefaultLimit rows are returned, NextPaginationToken should be empty.
 	if res.NextPaginationToken != nil && *res.NextPaginationToken != "" {
@@ -198,6 +215,41 @@ func TestListLikedYou(t *testing.T) {
 	}
 }
 
+// TestListLikedYou_Keyset verifies the token is the like time and id of the
+// last like on a full page and bounds the next one.
+func TestListLikedYou_Keyset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"})
+	for i := 0; i < service.DefaultLimit; i++ {
+		rows.AddRow(100-i, fmt.Sprintf("actor%d", i), 1700000000-i)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE recipient_user_id = ? AND liked_recipient = TRUE AND (decisions.updated_at < FROM_UNIXTIME(?) OR (decisions.updated_at = FROM_UNIXTIME(?) AND decisions.id < ?))`)).
+		WithArgs("recipient1", uint64(1700000001), uint64(1700000001), uint64(101), service.DefaultLimit).
+		WillReturnRows(rows)
+
+	srv := service.NewExploreServer(db)
+	res, err := srv.ListLikedYou(context.Background(), &pb.ListLikedYouRequest{
+		RecipientUserId: "recipient1",
+		PaginationToken: strPtr("1700000001:101"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := service.DefaultLimit - 1
+	if want := fmt.Sprintf("%d:%d", 1700000000-last, 100-last); res.GetNextPaginationToken() != want {
+		t.Errorf("expected next token %q, got %q", want, res.GetNextPaginationToken())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
 // TestListNewLikedYou tests the ListNewLikedYou endpoint.
 func TestListNewLikedYou(t *testing.T) {
 	db, mock, err := sqlmock.New()
@@ -210,20 +262,24 @@ func TestListNewLikedYou(t *testing.T) {
 	ctx := context.Background()
 
 	// Create rows to simulate one liker who hasn't been liked back.
-	rows := sqlmock.NewRows([]string{"actor_user_id"}).
-		AddRow("actor3")
+	rows := sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}).
+		AddRow(5, "actor3", 1700000000)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT d.actor_user_id
+		SELECT d.id, d.actor_user_id, UNIX_TIMESTAMP(d.updated_at)
 		FROM decisions d
 		WHERE d.recipient_user_id = ? AND d.liked_recipient = TRUE
 		  AND NOT EXISTS (
 			  SELECT 1 FROM decisions d2
 			  WHERE d2.actor_user_id = ? AND d2.recipient_user_id = d.actor_user_id AND d2.liked_recipient = TRUE
 		  )
-		ORDER BY d.id DESC
-		LIMIT ? OFFSET ?
+		  AND NOT EXISTS (
+			  SELECT 1 FROM abuse_flags f
+			  WHERE f.actor_user_id = d.actor_user_id AND f.cleared_at IS NULL
+		  )
+		ORDER BY d.updated_at DESC, d.id DESC
+		LIMIT ?
 	`)).
-		WithArgs("recipient2", "recipient2", service.DefaultLimit, 0).
+		WithArgs("recipient2", "recipient2", service.DefaultLimit).
 		WillReturnRows(rows)
 
 	req := &pb.ListLikedYouRequest{
@@ -246,6 +302,112 @@ func TestListNewLikedYou(t *testing.T) {
 	}
 }
 
+// TestListNewLikedYou_TimeFilter verifies since/until become bounds on the like time.
+func TestListNewLikedYou_TimeFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	srv := service.NewExploreServer(db)
+	since, until := uint64(1700000000), uint64(1700086400)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		WHERE d.recipient_user_id = ? AND d.liked_recipient = TRUE AND d.updated_at >= FROM_UNIXTIME(?) AND d.updated_at < FROM_UNIXTIME(?) AND (d.updated_at < FROM_UNIXTIME(?) OR (d.updated_at = FROM_UNIXTIME(?) AND d.id < ?))
+		  AND NOT EXISTS (`)).
+		WithArgs("recipient2", since, until, uint64(1700050000), uint64(1700050000), uint64(20), "recipient2", service.DefaultLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}))
+
+	res, err := srv.ListNewLikedYou(context.Background(), &pb.ListLikedYouRequest{
+		RecipientUserId:    "recipient2",
+		PaginationToken:    strPtr("1700050000:20"),
+		SinceUnixTimestamp: &since,
+		UntilUnixTimestamp: &until,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Likers) != 0 {
+		t.Errorf("expected no likers, got %d", len(res.Likers))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestListNewLikedYou_UndecidedOnly verifies undecided_only also hides likers
+// the recipient passed on.
+func TestListNewLikedYou_UndecidedOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	srv := service.NewExploreServer(db)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		WHERE d.recipient_user_id = ? AND d.liked_recipient = TRUE
+		  AND NOT EXISTS (
+			  SELECT 1 FROM decisions rd
+			  WHERE rd.actor_user_id = d.recipient_user_id AND rd.recipient_user_id = d.actor_user_id
+		  )`)).
+		WithArgs("recipient2", "recipient2", service.DefaultLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}).AddRow(5, "actor3", 1700000000))
+
+	undecided := true
+	res, err := srv.ListNewLikedYou(context.Background(), &pb.ListLikedYouRequest{
+		RecipientUserId: "recipient2",
+		UndecidedOnly:   &undecided,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Likers) != 1 {
+		t.Errorf("expected 1 liker, got %d", len(res.Likers))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCountLikedYou_UndecidedOnly verifies the count supports undecided_only.
+func TestCountLikedYou_UndecidedOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	srv := service.NewExploreServer(db)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		WHERE recipient_user_id = ? AND liked_recipient = TRUE
+		  AND NOT EXISTS (
+			  SELECT 1 FROM decisions rd
+			  WHERE rd.actor_user_id = decisions.recipient_user_id AND rd.recipient_user_id = decisions.actor_user_id
+		  )`)).
+		WithArgs("recipient3").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(2))
+
+	undecided := true
+	res, err := srv.CountLikedYou(context.Background(), &pb.CountLikedYouRequest{
+		RecipientUserId: "recipient3",
+		UndecidedOnly:   &undecided,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Count != 2 {
+		t.Errorf("expected count 2, got %d", res.Count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
 // TestCountLikedYou tests the CountLikedYou endpoint.
 func TestCountLikedYou(t *testing.T) {
 	db, mock, err := sqlmock.New()
@@ -261,6 +423,10 @@ func TestCountLikedYou(t *testing.T) {
 		SELECT COUNT(*)
 		FROM decisions
 		WHERE recipient_user_id = ? AND liked_recipient = TRUE
+		  AND NOT EXISTS (
+			  SELECT 1 FROM abuse_flags f
+			  WHERE f.actor_user_id = decisions.actor_user_id AND f.cleared_at IS NULL
+		  )
 	`)).
 		WithArgs("recipient3").
 		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(5))
@@ -280,3 +446,196 @@ func TestCountLikedYou(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %v", err)
 	}
 }
+
+// TestPutDecision_RecordsMetrics verifies that a mutual like is counted as a match.
+func TestPutDecision_RecordsMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	m := metrics.New()
+	srv := service.NewExploreServer(db, service.WithMetrics(m))
+	ctx := context.Background()
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions`)).
+		WithArgs("actor1", "recipient1", true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions`)).
+		WithArgs("recipient1", "actor1").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+
+	_, err = srv.PutDecision(ctx, &pb.PutDecisionRequest{
+		ActorUserId:     "actor1",
+		RecipientUserId: "recipient1",
+		LikedRecipient:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`explore_decisions_total{decision="like"} 1`,
+		`explore_matches_created_total 1`,
+		`explore_db_query_duration_seconds_count{operation="put_decision",outcome="ok"} 1`,
+		`explore_db_query_duration_seconds_count{operation="check_mutual_like",outcome="ok"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPutDecision_UnchangedMetrics verifies that re-sending a stored decision
+// leaves the decision and match counters where they were.
+func TestPutDecision_UnchangedMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	m := metrics.New()
+	srv := service.NewExploreServer(db, service.WithMetrics(m))
+	req := &pb.PutDecisionRequest{
+		ActorUserId:     "actor1",
+		RecipientUserId: "recipient1",
+		LikedRecipient:  true,
+	}
+
+	// The first like is stored; the repeat matches the stored row.
+	for _, affected := range []int64{1, 0} {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions`)).
+			WithArgs("actor1", "recipient1", true).
+			WillReturnResult(sqlmock.NewResult(0, affected))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions`)).
+			WithArgs("recipient1", "actor1").
+			WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+		if _, err := srv.PutDecision(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`explore_decisions_total{decision="like"} 1`,
+		`explore_matches_created_total 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q", want)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestPutDecision_Spans verifies that each SQL statement gets its own span.
+func TestPutDecision_Spans(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(exporter, "explore-test", 1)
+	srv := service.NewExploreServer(db, service.WithTracerProvider(tp))
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions`)).
+		WithArgs("actor1", "recipient1", true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions`)).
+		WithArgs("recipient1", "actor1").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "rpc")
+	_, err = srv.PutDecision(ctx, &pb.PutDecisionRequest{
+		ActorUserId:     "actor1",
+		RecipientUserId: "recipient1",
+		LikedRecipient:  true,
+	})
+	parent.End()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tp.ForceFlush(context.Background())
+
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "rpc" {
+			continue
+		}
+		names = append(names, span.Name)
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected %s to be a child of the RPC span", span.Name)
+		}
+	}
+	if strings.Join(names, ",") != "db.put_decision,db.check_mutual_like" {
+		t.Errorf("unexpected query spans: %v", names)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestListLikedYou_InvalidToken verifies that a malformed token is InvalidArgument.
+func TestListLikedYou_InvalidToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	srv := service.NewExploreServer(db)
+	_, err = srv.ListLikedYou(context.Background(), &pb.ListLikedYouRequest{
+		RecipientUserId: "recipient1",
+		PaginationToken: strPtr("not-a-number"),
+	})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCountLikedYou_DBError verifies that driver errors are not exposed to clients.
+func TestCountLikedYou_DBError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	srv := service.NewExploreServer(db)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*)`)).
+		WithArgs("recipient3").
+		WillReturnError(errors.New("Error 1146: Table 'mydb.decisions' doesn't exist"))
+
+	_, err = srv.CountLikedYou(context.Background(), &pb.CountLikedYouRequest{RecipientUserId: "recipient3"})
+	st := status.Convert(err)
+	if st.Code() != codes.Internal {
+		t.Errorf("expected Internal, got %v", st.Code())
+	}
+	if strings.Contains(st.Message(), "mydb.decisions") {
+		t.Errorf("status message %q leaks the SQL error", st.Message())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/KEdore/explore/internal/apperr"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// IdempotencyKeyHeader is the metadata alternative to PutDecisionRequest.idempotency_key.
+const IdempotencyKeyHeader = "idempotency-key"
+
+// Reasons for idempotency failures.
+const (
+	ReasonIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	ReasonRequestInProgress    = "REQUEST_IN_PROGRESS"
+)
+
+// idempotencyLease is how long an in-flight reservation holds its key. A
+// request that has not completed by then is presumed lost, and a retry with
+// the same key takes the reservation over.
+const idempotencyLease = 30 * time.Second
+
+// WithIdempotency stores PutDecision results under their idempotency key for
+// ttl so retries get the original response. A zero ttl disables it.
+func WithIdempotency(ttl time.Duration) Option {
+	return func(s *ExploreServer) { s.idempotencyTTL = ttl }
+}
+
+// idempotencyKey returns the key from the request field, falling back to metadata.
+func idempotencyKey(ctx context.Context, req *pb.PutDecisionRequest) string {
+	if req.IdempotencyKey != nil {
+		return req.GetIdempotencyKey()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(IdempotencyKeyHeader); len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// decisionHash fingerprints the parts of the request that define its effect.
+func decisionHash(req *pb.PutDecisionRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%q|%q|%t", req.GetActorUserId(), req.GetRecipientUserId(), req.GetLikedRecipient())))
+	return hex.EncodeToString(sum[:])
+}
+
+// reserveIdempotencyKey claims key for this request. It returns owned=true if
+// the caller should execute the request, or the stored response to replay.
+// A reservation is held for idempotencyLease until completion extends it to
+// the full TTL. Expired reservations are taken over in place.
+func (s *ExploreServer) reserveIdempotencyKey(ctx context.Context, actor, key, hash string) (owned bool, replay *pb.PutDecisionResponse, err error) {
+	// expires_at is assigned last so the IF()s above it still see the old value.
+	reserveQuery := `
+		INSERT INTO idempotency_keys (actor_user_id, idempotency_key, request_hash, expires_at)
+		VALUES (?, ?, ?, NOW() + INTERVAL ? SECOND)
+		ON DUPLICATE KEY UPDATE
+			request_hash = IF(expires_at <= NOW(), VALUES(request_hash), request_hash),
+			response = IF(expires_at <= NOW(), NULL, response),
+			expires_at = IF(expires_at <= NOW(), VALUES(expires_at), expires_at)
+	`
+	var affected int64
+	err = s.observe(ctx, "reserve_idempotency_key", func(ctx context.Context) error {
+		res, err := s.db.ExecContext(ctx, reserveQuery, actor, key, hash, int64(s.leaseDuration()/time.Second))
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return false, nil, apperr.FromDB(err, "failed to reserve idempotency key")
+	}
+	// 1 = inserted, 2 = took over an expired row, 0 = a live row already exists.
+	if affected > 0 {
+		return true, nil, nil
+	}
+
+	lookupQuery := `
+		SELECT request_hash, response FROM idempotency_keys
+		WHERE actor_user_id = ? AND idempotency_key = ?
+	`
+	var storedHash string
+	var response []byte
+	err = s.observe(ctx, "lookup_idempotency_key", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, lookupQuery, actor, key).Scan(&storedHash, &response)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Purged between the two statements; treat as a fresh request.
+		return s.reserveIdempotencyKey(ctx, actor, key, hash)
+	}
+	if err != nil {
+		return false, nil, apperr.FromDB(err, "failed to look up idempotency key")
+	}
+
+	if storedHash != hash {
+		return false, nil, apperr.InvalidArgument(ReasonIdempotencyKeyReused, "idempotency key was already used with a different request").
+			With("field", "idempotency_key")
+	}
+	if response == nil {
+		return false, nil, apperr.New(codes.Aborted, ReasonRequestInProgress, "a request with this idempotency key is still in progress")
+	}
+	replay = &pb.PutDecisionResponse{}
+	if err := proto.Unmarshal(response, replay); err != nil {
+		return false, nil, apperr.Internal(fmt.Errorf("decode stored response: %w", err))
+	}
+	return false, replay, nil
+}
+
+// leaseDuration caps the in-flight lease at the TTL.
+func (s *ExploreServer) leaseDuration() time.Duration {
+	return min(idempotencyLease, s.idempotencyTTL)
+}
+
+// completeIdempotencyKey stores the response for replays and keeps it for the
+// full TTL. A reservation that lapsed and was taken over by a different
+// request is left to its new owner.
+func (s *ExploreServer) completeIdempotencyKey(ctx context.Context, actor, key, hash string, res *pb.PutDecisionResponse) error {
+	body, err := proto.Marshal(res)
+	if err != nil {
+		return apperr.Internal(err)
+	}
+	query := `
+		UPDATE idempotency_keys SET response = ?, expires_at = NOW() + INTERVAL ? SECOND
+		WHERE actor_user_id = ? AND idempotency_key = ? AND request_hash = ?
+	`
+	err = s.observe(ctx, "complete_idempotency_key", func(ctx context.Context) error {
+		_, err := s.db.ExecContext(ctx, query, body, int64(s.idempotencyTTL/time.Second), actor, key, hash)
+		return err
+	})
+	if err != nil {
+		return apperr.FromDB(err, "failed to store idempotent response")
+	}
+	return nil
+}
+
+// releaseIdempotencyKey drops a reservation whose request failed so the
+// client can retry with the same key.
+func (s *ExploreServer) releaseIdempotencyKey(ctx context.Context, actor, key, hash string) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE actor_user_id = ? AND idempotency_key = ? AND request_hash = ? AND response IS NULL
+	`
+	// The request context may already be done; the release must still happen.
+	ctx = context.WithoutCancel(ctx)
+	s.observe(ctx, "release_idempotency_key", func(ctx context.Context) error {
+		_, err := s.db.ExecContext(ctx, query, actor, key, hash)
+		return err
+	})
+}
+
+// PurgeExpiredIdempotencyKeys deletes up to limit expired keys and reports how many were removed.
+func (s *ExploreServer) PurgeExpiredIdempotencyKeys(ctx context.Context, limit int) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= NOW() LIMIT ?`
+	var n int64
+	err := s.observe(ctx, "purge_idempotency_keys", func(ctx context.Context) error {
+		res, err := s.db.ExecContext(ctx, query, limit)
+		if err != nil {
+			return err
+		}
+		n, err = res.RowsAffected()
+		return err
+	})
+	return n, err
+}
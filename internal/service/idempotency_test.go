@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+const (
+	reserveKeyQuery  = `INSERT INTO idempotency_keys`
+	lookupKeyQuery   = `SELECT request_hash, response FROM idempotency_keys`
+	completeKeyQuery = `UPDATE idempotency_keys SET response`
+	releaseKeyQuery  = `DELETE FROM idempotency_keys`
+	putQuery         = `INSERT INTO decisions`
+)
+
+func idempotentRequest() *pb.PutDecisionRequest {
+	return &pb.PutDecisionRequest{
+		ActorUserId:     "actor1",
+		RecipientUserId: "recipient1",
+		LikedRecipient:  false,
+		IdempotencyKey:  strPtr("key-1"),
+	}
+}
+
+// storedHash runs a fresh request against mock to capture the hash the
+// service stores for req.
+func storedHash(t *testing.T, req *pb.PutDecisionRequest) string {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	var hash string
+	mock.ExpectExec(reserveKeyQuery).
+		WithArgs(req.GetActorUserId(), req.GetIdempotencyKey(), captureArg{&hash}, int64(30)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(putQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(completeKeyQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	srv := service.NewExploreServer(db, service.WithIdempotency(time.Hour))
+	if _, err := srv.PutDecision(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return hash
+}
+
+// captureArg matches any string argument and records it.
+type captureArg struct{ dst *string }
+
+func (c captureArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if ok {
+		*c.dst = s
+	}
+	return ok
+}
+
+func TestPutDecision_IdempotencyFirstRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	want, _ := proto.Marshal(&pb.PutDecisionResponse{})
+	mock.ExpectExec(reserveKeyQuery).
+		WithArgs("actor1", "key-1", sqlmock.AnyArg(), int64(30)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(putQuery).
+		WithArgs("actor1", "recipient1", false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Completion extends the short in-flight lease to the full TTL.
+	mock.ExpectExec(completeKeyQuery).
+		WithArgs(want, int64(3600), "actor1", "key-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	srv := service.NewExploreServer(db, service.WithIdempotency(time.Hour))
+	if _, err := srv.PutDecision(context.Background(), idempotentRequest()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPutDecision_IdempotencyReplay(t *testing.T) {
+	req := idempotentRequest()
+	hash := storedHash(t, req)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	// MutualLikes can only come from the stored response for a pass.
+	stored, _ := proto.Marshal(&pb.PutDecisionResponse{MutualLikes: true})
+	mock.ExpectExec(reserveKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(lookupKeyQuery).
+		WithArgs("actor1", "key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response"}).AddRow(hash, stored))
+
+	srv := service.NewExploreServer(db, service.WithIdempotency(time.Hour))
+	res, err := srv.PutDecision(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.GetMutualLikes() {
+		t.Errorf("expected the stored response to be replayed")
+	}
+	// No decision write may happen on replay.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPutDecision_IdempotencyConflicts(t *testing.T) {
+	req := idempotentRequest()
+	hash := storedHash(t, req)
+
+	tests := []struct {
+		name     string
+		hash     string
+		response []byte
+		code     codes.Code
+	}{
+		{name: "key reused", hash: "other", response: []byte{}, code: codes.InvalidArgument},
+		{name: "in progress", hash: hash, response: nil, code: codes.Aborted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to open sqlmock database: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectExec(reserveKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(lookupKeyQuery).
+				WillReturnRows(sqlmock.NewRows([]string{"request_hash", "response"}).AddRow(tt.hash, tt.response))
+
+			srv := service.NewExploreServer(db, service.WithIdempotency(time.Hour))
+			_, err = srv.PutDecision(context.Background(), req)
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("expected %v, got %v (%v)", tt.code, got, err)
+			}
+		})
+	}
+}
+
+func TestPutDecision_IdempotencyReleasedOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(reserveKeyQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(putQuery).WillReturnError(errors.New("boom"))
+	mock.ExpectExec(releaseKeyQuery).
+		WithArgs("actor1", "key-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// The key arrives as metadata instead of the request field.
+	req := idempotentRequest()
+	req.IdempotencyKey = nil
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(service.IdempotencyKeyHeader, "key-1"))
+
+	srv := service.NewExploreServer(db, service.WithIdempotency(time.Hour))
+	if _, err := srv.PutDecision(ctx, req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MaxImportBatch is 65535 placeholders divided by the five columns per row.
+const MaxImportBatch = 13000
+
+// ImportedDecision is a historical decision loaded by ImportDecisions.
+type ImportedDecision struct {
+	Actor     string
+	Recipient string
+	Liked     bool
+	Time      time.Time
+}
+
+// ImportDecisions upserts a batch of historical decisions in one transaction.
+// A stored decision is only replaced by an imported one that is at least as
+// recent; created_at keeps the earliest time seen. Archived pairs are moved
+// back first whether or not this server archives, since imports run outside
+// the server and a pair must never be in both tables.
+func (s *ExploreServer) ImportDecisions(ctx context.Context, rows []ImportedDecision) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(`INSERT INTO decisions (actor_user_id, recipient_user_id, liked_recipient, created_at, updated_at) VALUES `)
+	args := make([]any, 0, len(rows)*5)
+	keys := make([]any, 0, len(rows)*2)
+	for i, r := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(?, ?, ?, ?, ?)")
+		args = append(args, r.Actor, r.Recipient, r.Liked, r.Time, r.Time)
+		keys = append(keys, r.Actor, r.Recipient)
+	}
+	// updated_at is assigned last so the comparison above it sees the stored value.
+	b.WriteString(`
+		ON DUPLICATE KEY UPDATE
+			liked_recipient = IF(VALUES(updated_at) >= updated_at, VALUES(liked_recipient), liked_recipient),
+			created_at = LEAST(created_at, VALUES(created_at)),
+			updated_at = GREATEST(updated_at, VALUES(updated_at))`)
+	query := b.String()
+
+	return s.putUnarchived(ctx, keys, func(ctx context.Context, db execer) error {
+		return s.observe(ctx, "import_decisions", func(ctx context.Context) error {
+			_, err := db.ExecContext(ctx, query, args...)
+			return err
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// TestImportDecisions_OldLikeSortsByTime verifies that an imported like
+// keeps its historical time and is listed after a live like made since, even
+// though the import gave it the higher id.
+func TestImportDecisions_OldLikeSortsByTime(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	old := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions WHERE (actor_user_id, recipient_user_id) IN ((?, ?)) FOR UPDATE`)).
+		WithArgs("old-liker", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM decisions_archive`)).
+		WithArgs("old-liker", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO decisions (actor_user_id, recipient_user_id, liked_recipient, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`)).
+		WithArgs("old-liker", "bob", true, old, old).
+		WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectCommit()
+
+	srv := service.NewExploreServer(db)
+	err = srv.ImportDecisions(context.Background(), []service.ImportedDecision{
+		{Actor: "old-liker", Recipient: "bob", Liked: true, Time: old},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The live like has the lower id but the later time.
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY updated_at DESC, id DESC`)).
+		WithArgs("bob", service.DefaultLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}).
+			AddRow(5, "live-liker", 1700000000).
+			AddRow(9, "old-liker", old.Unix()))
+
+	res, err := srv.ListLikedYou(context.Background(), &pb.ListLikedYouRequest{RecipientUserId: "bob"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, l := range res.GetLikers() {
+		got = append(got, l.GetActorId())
+	}
+	if len(got) != 2 || got[0] != "live-liker" || got[1] != "old-liker" {
+		t.Errorf("expected the live like first, got %v", got)
+	}
+	if ts := res.GetLikers()[1].GetUnixTimestamp(); ts != uint64(old.Unix()) {
+		t.Errorf("expected the imported like's time %d, got %d", old.Unix(), ts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/KEdore/explore/internal/apperr"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// MaxSeenActors bounds the actor_user_ids of one MarkLikesSeen request.
+const MaxSeenActors = 100
+
+// unseenCondition restricts likes in table to those the recipient has not
+// seen, either through the watermark or individually.
+func unseenCondition(table string) string {
+	return `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM like_watermarks w
+			  WHERE w.recipient_user_id = ` + table + `.recipient_user_id AND w.seen_until >= ` + table + `.updated_at
+		  )
+		  AND NOT EXISTS (
+			  SELECT 1 FROM seen_likes sl
+			  WHERE sl.recipient_user_id = ` + table + `.recipient_user_id AND sl.actor_user_id = ` + table + `.actor_user_id
+			    AND sl.seen_at >= ` + table + `.updated_at
+		  )`
+}
+
+// MarkLikesSeen marks likes from actor_user_ids as seen and moves the
+// recipient's watermark forward to seen_until_unix_timestamp, or to now when
+// no actors are given. The watermark never moves back or past now.
+func (s *ExploreServer) MarkLikesSeen(ctx context.Context, req *pb.MarkLikesSeenRequest) (*pb.MarkLikesSeenResponse, error) {
+	recipient := req.GetRecipientUserId()
+	actors := req.GetActorUserIds()
+	moveWatermark := req.SeenUntilUnixTimestamp != nil || len(actors) == 0
+	var seenUntil any
+	if req.SeenUntilUnixTimestamp != nil {
+		seenUntil = req.GetSeenUntilUnixTimestamp()
+	}
+
+	res := &pb.MarkLikesSeenResponse{}
+	err := s.observe(ctx, "mark_likes_seen", func(ctx context.Context) error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if len(actors) > 0 {
+			args := make([]any, 0, 2*len(actors))
+			for _, actor := range actors {
+				args = append(args, recipient, actor)
+			}
+			query := `INSERT INTO seen_likes (recipient_user_id, actor_user_id) VALUES ` +
+				strings.TrimSuffix(strings.Repeat("(?, ?), ", len(actors)), ", ") + `
+				ON DUPLICATE KEY UPDATE seen_at = CURRENT_TIMESTAMP`
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return err
+			}
+		}
+
+		if moveWatermark {
+			upsert := `
+				INSERT INTO like_watermarks (recipient_user_id, seen_until)
+				VALUES (?, LEAST(COALESCE(FROM_UNIXTIME(?), NOW()), NOW()))
+				ON DUPLICATE KEY UPDATE
+					seen_until = GREATEST(seen_until, VALUES(seen_until))
+			`
+			if _, err := tx.ExecContext(ctx, upsert, recipient, seenUntil); err != nil {
+				return err
+			}
+			// Individually seen likes behind the watermark are now redundant.
+			prune := `
+				DELETE FROM seen_likes
+				WHERE recipient_user_id = ?
+				  AND seen_at <= (SELECT seen_until FROM like_watermarks WHERE recipient_user_id = ?)
+			`
+			if _, err := tx.ExecContext(ctx, prune, recipient, recipient); err != nil {
+				return err
+			}
+		}
+
+		err = tx.QueryRowContext(ctx,
+			`SELECT UNIX_TIMESTAMP(seen_until) FROM like_watermarks WHERE recipient_user_id = ?`,
+			recipient).Scan(&res.SeenUntilUnixTimestamp)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return nil, apperr.FromDB(err, "failed to mark likes seen")
+	}
+	return res, nil
+}
+
+// CountUnseenLikedYou counts the likes ListNewLikedYou would return with
+// unseen_only set, for the app badge.
+func (s *ExploreServer) CountUnseenLikedYou(ctx context.Context, req *pb.CountLikedYouRequest) (*pb.CountLikedYouResponse, error) {
+	filter := s.liveLike("d") + unseenCondition("d")
+	if req.GetUndecidedOnly() {
+		filter += s.undecidedCondition("d")
+	}
+	query := `
+		SELECT COUNT(*)
+		FROM decisions d
+		WHERE d.recipient_user_id = ? AND d.liked_recipient = TRUE` + filter + `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM decisions d2
+			  WHERE d2.actor_user_id = ? AND d2.recipient_user_id = d.actor_user_id AND d2.liked_recipient = TRUE` + s.liveLike("d2") + `
+		  )` + s.archivedReverse("d", " AND ra.liked_recipient = TRUE"+s.liveLike("ra")) + `
+		  AND NOT EXISTS (
+			  SELECT 1 FROM abuse_flags f
+			  WHERE f.actor_user_id = d.actor_user_id AND f.cleared_at IS NULL
+		  )
+	`
+	var count int
+	err := s.observe(ctx, "count_unseen_liked_you", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, query, req.GetRecipientUserId(), req.GetRecipientUserId()).Scan(&count)
+	})
+	if err != nil {
+		return nil, apperr.FromDB(err, "failed to count unseen liked decisions")
+	}
+	return &pb.CountLikedYouResponse{
+		Count: uint64(count),
+	}, nil
+}
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+const watermarkQuery = `SELECT UNIX_TIMESTAMP(seen_until) FROM like_watermarks`
+
+// TestMarkLikesSeen_Actors verifies that marking actors leaves the watermark alone.
+func TestMarkLikesSeen_Actors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO seen_likes (recipient_user_id, actor_user_id) VALUES (?, ?), (?, ?)`)).
+		WithArgs("bob", "alice", "bob", "carol").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(regexp.QuoteMeta(watermarkQuery)).
+		WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"seen_until"}))
+	mock.ExpectCommit()
+
+	srv := service.NewExploreServer(db)
+	res, err := srv.MarkLikesSeen(context.Background(), &pb.MarkLikesSeenRequest{
+		RecipientUserId: "bob",
+		ActorUserIds:    []string{"alice", "carol"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GetSeenUntilUnixTimestamp() != 0 {
+		t.Errorf("expected no watermark, got %d", res.GetSeenUntilUnixTimestamp())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestMarkLikesSeen_Watermark verifies the watermark moves and prunes
+// individually seen likes behind it.
+func TestMarkLikesSeen_Watermark(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	until := uint64(1700000000)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO like_watermarks`)).
+		WithArgs("bob", until).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM seen_likes`)).
+		WithArgs("bob", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(regexp.QuoteMeta(watermarkQuery)).
+		WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"seen_until"}).AddRow(until))
+	mock.ExpectCommit()
+
+	srv := service.NewExploreServer(db)
+	res, err := srv.MarkLikesSeen(context.Background(), &pb.MarkLikesSeenRequest{
+		RecipientUserId:        "bob",
+		SeenUntilUnixTimestamp: &until,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GetSeenUntilUnixTimestamp() != until {
+		t.Errorf("expected watermark %d, got %d", until, res.GetSeenUntilUnixTimestamp())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestCountUnseenLikedYou verifies the count applies the seen conditions.
+func TestCountUnseenLikedYou(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?s)SELECT COUNT\(\*\).*FROM like_watermarks w.*FROM seen_likes sl.*FROM decisions d2`).
+		WithArgs("bob", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(4))
+
+	srv := service.NewExploreServer(db)
+	res, err := srv.CountUnseenLikedYou(context.Background(), &pb.CountLikedYouRequest{RecipientUserId: "bob"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.GetCount() != 4 {
+		t.Errorf("expected count 4, got %d", res.GetCount())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+// TestListLikedYou_UnseenOnly verifies unseen_only adds the seen conditions.
+func TestListLikedYou_UnseenOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`(?s)FROM decisions\s+WHERE recipient_user_id = \? AND liked_recipient = TRUE.*w\.seen_until >= decisions\.updated_at.*sl\.seen_at >= decisions\.updated_at`).
+		WithArgs("bob", service.DefaultLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_user_id", "UNIX_TIMESTAMP(updated_at)"}).AddRow(3, "alice", 1700000000))
+
+	unseen := true
+	srv := service.NewExploreServer(db)
+	res, err := srv.ListLikedYou(context.Background(), &pb.ListLikedYouRequest{RecipientUserId: "bob", UnseenOnly: &unseen})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.GetLikers()) != 1 {
+		t.Errorf("expected 1 liker, got %d", len(res.GetLikers()))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/KEdore/explore/internal/validation"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// MaxUserIDLen matches the VARCHAR(255) user ID columns.
+const MaxUserIDLen = 255
+
+// DefaultUserIDPattern accepts the ID alphabets used by our identity providers.
+const DefaultUserIDPattern = `^[A-Za-z0-9_.:@-]+$`
+
+// MaxIdempotencyKeyLen matches the idempotency_keys.idempotency_key column.
+const MaxIdempotencyKeyLen = 255
+
+// maxPaginationTokenLen bounds tokens before they are parsed.
+const maxPaginationTokenLen = 64
+
+// NewValidator returns the request rules for ExploreService and
+// ExploreAdminService. User IDs must be present, fit the column and match
+// userIDPattern.
+func NewValidator(userIDPattern *regexp.Regexp) *validation.Validator {
+	userID := []validation.StringRule{
+		validation.Required(),
+		validation.MaxLen(MaxUserIDLen),
+		validation.Matches(userIDPattern),
+	}
+	v := validation.New()
+
+	validation.Register(v,
+		validation.Field("actor_user_id", (*pb.PutDecisionRequest).GetActorUserId, userID...),
+		validation.Field("recipient_user_id", (*pb.PutDecisionRequest).GetRecipientUserId, userID...),
+		validation.NotEqual("recipient_user_id", "actor_user_id",
+			(*pb.PutDecisionRequest).GetRecipientUserId, (*pb.PutDecisionRequest).GetActorUserId),
+		validation.Field("idempotency_key", (*pb.PutDecisionRequest).GetIdempotencyKey,
+			validation.MaxLen(MaxIdempotencyKeyLen)),
+	)
+	validation.Register(v,
+		validation.Field("recipient_user_id", (*pb.ListLikedYouRequest).GetRecipientUserId, userID...),
+		validation.Field("pagination_token", (*pb.ListLikedYouRequest).GetPaginationToken,
+			validation.MaxLen(maxPaginationTokenLen)),
+		likeTimeRange,
+	)
+	validation.Register(v,
+		validation.Field("recipient_user_id", (*pb.CountLikedYouRequest).GetRecipientUserId, userID...),
+	)
+	validation.Register(v,
+		validation.Field("recipient_user_id", (*pb.MarkLikesSeenRequest).GetRecipientUserId, userID...),
+		seenActors(userID),
+	)
+	validation.Register(v,
+		validation.Field("pagination_token", (*pb.ListAbuseFlagsRequest).GetPaginationToken,
+			validation.MaxLen(maxPaginationTokenLen)),
+	)
+	validation.Register(v,
+		validation.Field("actor_user_id", (*pb.ClearAbuseFlagRequest).GetActorUserId, userID...),
+	)
+	validation.Register(v,
+		validation.Field("user_id", (*pb.EraseUserRequest).GetUserId, userID...),
+	)
+	return v
+}
+
+// likeTimeRange rejects a since/until window that cannot hold any likes.
+func likeTimeRange(req *pb.ListLikedYouRequest) []validation.Violation {
+	if req.SinceUnixTimestamp != nil && req.UntilUnixTimestamp != nil &&
+		req.GetSinceUnixTimestamp() >= req.GetUntilUnixTimestamp() {
+		return []validation.Violation{{Field: "until_unix_timestamp", Description: "must be after since_unix_timestamp"}}
+	}
+	return nil
+}
+
+// seenActors applies the user ID rules to every actor_user_ids entry.
+func seenActors(userID []validation.StringRule) validation.Rule[*pb.MarkLikesSeenRequest] {
+	return func(req *pb.MarkLikesSeenRequest) []validation.Violation {
+		ids := req.GetActorUserIds()
+		if len(ids) > MaxSeenActors {
+			return []validation.Violation{{Field: "actor_user_ids", Description: fmt.Sprintf("must hold at most %d IDs", MaxSeenActors)}}
+		}
+		var out []validation.Violation
+		for i, id := range ids {
+			field := fmt.Sprintf("actor_user_ids[%d]", i)
+			out = append(out, validation.Field(field, func(*pb.MarkLikesSeenRequest) string { return id }, userID...)(req)...)
+		}
+		return out
+	}
+}
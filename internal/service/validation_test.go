@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/service"
+	pb "github.com/KEdore/explore/proto"
+)
+
+// fieldViolations returns the BadRequest field names carried by err.
+func fieldViolations(t *testing.T, err error) []string {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", st.Code())
+	}
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+		}
+	}
+	return fields
+}
+
+func u64Ptr(v uint64) *uint64 { return &v }
+
+// TestValidator verifies the ExploreService request rules.
+func TestValidator(t *testing.T) {
+	v := service.NewValidator(regexp.MustCompile(service.DefaultUserIDPattern))
+
+	valid := []any{
+		&pb.PutDecisionRequest{ActorUserId: "alice", RecipientUserId: "bob", LikedRecipient: true},
+		&pb.ListLikedYouRequest{RecipientUserId: "user-42"},
+		&pb.CountLikedYouRequest{RecipientUserId: "auth0:abc123"},
+	}
+	for _, req := range valid {
+		if err := v.Validate(req); err != nil {
+			t.Errorf("expected %T to be valid, got %v", req, err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		req    any
+		fields []string
+	}{
+		{"empty IDs", &pb.PutDecisionRequest{}, []string{"actor_user_id", "recipient_user_id"}},
+		{"self like", &pb.PutDecisionRequest{ActorUserId: "alice", RecipientUserId: "alice"}, []string{"recipient_user_id"}},
+		{"too long", &pb.CountLikedYouRequest{RecipientUserId: strings.Repeat("a", service.MaxUserIDLen+1)}, []string{"recipient_user_id"}},
+		{"bad characters", &pb.ListLikedYouRequest{RecipientUserId: "bob; DROP TABLE decisions"}, []string{"recipient_user_id"}},
+		{"long token", &pb.ListLikedYouRequest{RecipientUserId: "bob", PaginationToken: strPtr(strings.Repeat("9", 100))}, []string{"pagination_token"}},
+		{"bad seen actor", &pb.MarkLikesSeenRequest{RecipientUserId: "bob", ActorUserIds: []string{"alice", ""}}, []string{"actor_user_ids[1]"}},
+		{"too many seen actors", &pb.MarkLikesSeenRequest{RecipientUserId: "bob", ActorUserIds: make([]string, service.MaxSeenActors+1)}, []string{"actor_user_ids"}},
+		{"inverted window", &pb.ListLikedYouRequest{RecipientUserId: "bob", SinceUnixTimestamp: u64Ptr(200), UntilUnixTimestamp: u64Ptr(100)}, []string{"until_unix_timestamp"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := fieldViolations(t, v.Validate(tc.req))
+			if strings.Join(got, ",") != strings.Join(tc.fields, ",") {
+				t.Errorf("expected violations %v, got %v", tc.fields, got)
+			}
+		})
+	}
+}
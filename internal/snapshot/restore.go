@@ -0,0 +1,277 @@
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultRestoreBatchRows is how many rows each restore INSERT carries.
+const DefaultRestoreBatchRows = 500
+
+// ReadManifest loads dir's manifest.
+func ReadManifest(dir string) (*Manifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Verify checks every chunk against the manifest's checksums and row counts
+// and that the manifest only names known tables.
+func Verify(dir string) (*Manifest, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, tm := range m.Tables {
+		if !knownTable(tm.Name) {
+			return nil, fmt.Errorf("manifest names unknown table %q", tm.Name)
+		}
+		var total int64
+		for _, c := range tm.Chunks {
+			n, err := verifyChunk(dir, c)
+			if err != nil {
+				return nil, fmt.Errorf("chunk %s: %w", c.File, err)
+			}
+			total += n
+		}
+		if total != tm.Rows {
+			return nil, fmt.Errorf("table %s: manifest lists %d rows, chunks hold %d", tm.Name, tm.Rows, total)
+		}
+	}
+	return m, nil
+}
+
+func verifyChunk(dir string, c Chunk) (int64, error) {
+	f, err := os.Open(chunkPath(dir, c))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	gz, err := gzip.NewReader(io.TeeReader(f, sum))
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	sc := bufio.NewScanner(gz)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	// Drain anything after the gzip stream so the hash covers the whole file.
+	if _, err := io.Copy(io.Discard, f); err != nil {
+		return 0, err
+	}
+	if got := hex.EncodeToString(sum.Sum(nil)); got != c.SHA256 {
+		return 0, fmt.Errorf("checksum mismatch: manifest %s, file %s", c.SHA256, got)
+	}
+	if n != c.Rows {
+		return 0, fmt.Errorf("manifest lists %d rows, file holds %d", c.Rows, n)
+	}
+	return n, nil
+}
+
+// Restorer loads snapshots. Each chunk is loaded in one transaction, so a
+// failed restore leaves every table at a chunk boundary.
+type Restorer struct {
+	DB        *sql.DB
+	BatchRows int
+	// Resume continues a failed restore of the same snapshot from the chunk
+	// boundary each table reached, instead of requiring empty tables.
+	Resume bool
+}
+
+// Restore verifies the snapshot in dir and loads it. The database must be
+// migrated to the snapshot's schema version and its tables must be empty,
+// or with Resume, partly loaded from this snapshot.
+func (r *Restorer) Restore(ctx context.Context, dir string) (*Manifest, error) {
+	m, err := Verify(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int
+	if err := r.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+	if version != m.SchemaVersion {
+		return nil, fmt.Errorf("database is at schema version %d, snapshot was taken at %d", version, m.SchemaVersion)
+	}
+	// loaded[i] is how many of table i's chunks are already in the database.
+	loaded := make([]int, len(m.Tables))
+	for i, tm := range m.Tables {
+		if err := r.checkTable(ctx, tm); err != nil {
+			return nil, err
+		}
+		if !r.Resume {
+			continue
+		}
+		n, err := r.loadedChunks(ctx, tm)
+		if err != nil {
+			return nil, err
+		}
+		// Tables load in order, so only the first incomplete one may be partial.
+		if i > 0 && n > 0 && loaded[i-1] < len(m.Tables[i-1].Chunks) {
+			return nil, fmt.Errorf("table %s has rows but %s is incomplete", tm.Name, m.Tables[i-1].Name)
+		}
+		loaded[i] = n
+	}
+
+	for i, tm := range m.Tables {
+		for _, c := range tm.Chunks[loaded[i]:] {
+			if err := r.loadChunk(ctx, dir, tm, c); err != nil {
+				return nil, fmt.Errorf("restore %s: %w", c.File, err)
+			}
+		}
+	}
+	return m, nil
+}
+
+// loadedChunks maps the table's row count to the number of whole chunks it
+// holds. Chunks commit atomically, so any other count means the table holds
+// data from elsewhere.
+func (r *Restorer) loadedChunks(ctx context.Context, tm TableManifest) (int, error) {
+	var rows int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+tm.Name).Scan(&rows); err != nil {
+		return 0, err
+	}
+	var total int64
+	for i, c := range tm.Chunks {
+		if total == rows {
+			return i, nil
+		}
+		total += c.Rows
+	}
+	if total == rows {
+		return len(tm.Chunks), nil
+	}
+	return 0, fmt.Errorf("table %s has %d rows, which is not a chunk boundary of this snapshot", tm.Name, rows)
+}
+
+// checkTable requires the table to have exactly the snapshot's columns,
+// which also keeps manifest column names out of SQL unless they are real
+// columns. Unless resuming, the table must also be empty.
+func (r *Restorer) checkTable(ctx context.Context, tm TableManifest) error {
+	rows, err := r.DB.QueryContext(ctx, `SELECT * FROM `+tm.Name+` LIMIT 1`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(cols, tm.Columns) {
+		return fmt.Errorf("table %s has columns %v, snapshot has %v", tm.Name, cols, tm.Columns)
+	}
+	if rows.Next() && !r.Resume {
+		return fmt.Errorf("table %s is not empty; use resume to continue a failed restore", tm.Name)
+	}
+	return rows.Err()
+}
+
+// loadChunk inserts one chunk in a single transaction.
+func (r *Restorer) loadChunk(ctx context.Context, dir string, tm TableManifest, c Chunk) error {
+	f, err := os.Open(chunkPath(dir, c))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	batchRows := r.BatchRows
+	if batchRows <= 0 {
+		batchRows = DefaultRestoreBatchRows
+	}
+	prefix := `INSERT INTO ` + tm.Name + ` (` + strings.Join(tm.Columns, ", ") + `) VALUES `
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(tm.Columns)), ", ") + ")"
+
+	var (
+		args []any
+		n    int
+	)
+	flush := func() error {
+		if n == 0 {
+			return nil
+		}
+		query := prefix + strings.TrimSuffix(strings.Repeat(placeholders+", ", n), ", ")
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		args, n = args[:0], 0
+		return nil
+	}
+
+	dec := json.NewDecoder(gz)
+	dec.UseNumber()
+	for {
+		var row []any
+		if err := dec.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if len(row) != len(tm.Columns) {
+			return fmt.Errorf("row has %d values, expected %d", len(row), len(tm.Columns))
+		}
+		for _, v := range row {
+			if num, ok := v.(json.Number); ok {
+				v = num.String()
+			}
+			args = append(args, v)
+		}
+		n++
+		if n == batchRows {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// chunkPath keeps chunk files inside dir whatever the manifest says.
+func chunkPath(dir string, c Chunk) string {
+	return filepath.Join(dir, filepath.Base(c.File))
+}
+
+func knownTable(name string) bool {
+	for _, t := range Tables {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+// Package snapshot writes consistent, checksummed exports of the decisions
+// dataset and restores them into an empty database.
+//
+// A snapshot is a directory holding gzip-compressed JSON Lines chunks, one
+// JSON array of column values per row, and a manifest.json listing every
+// chunk with its row count and SHA-256.
+package snapshot
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFile is the name of the manifest inside a snapshot directory.
+const ManifestFile = "manifest.json"
+
+// DefaultChunkRows is how many rows each chunk file holds.
+const DefaultChunkRows = 100000
+
+// timeLayout writes timestamps the way MySQL reads them back, so values
+// round-trip in whatever session time zone both sides use.
+const timeLayout = "2006-01-02 15:04:05.999999"
+
+// Table is a table included in snapshots, ordered by its primary key.
+type Table struct {
+	Name    string
+	OrderBy string
+}
+
+// Tables lists the snapshotted tables in restore order. Idempotency keys
+// are short-lived retry state and are left out.
+var Tables = []Table{
+	{"decisions", "actor_user_id, recipient_user_id"},
+	{"decisions_archive", "actor_user_id, recipient_user_id"},
+	{"actor_activity", "actor_user_id"},
+	{"abuse_flags", "actor_user_id"},
+	{"user_erasures", "subject_hash"},
+	{"like_watermarks", "recipient_user_id"},
+	{"seen_likes", "recipient_user_id, actor_user_id"},
+}
+
+// Manifest describes a snapshot.
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	// SchemaVersion is the latest migration applied to the source database.
+	SchemaVersion int             `json:"schema_version"`
+	Tables        []TableManifest `json:"tables"`
+}
+
+// TableManifest lists one table's columns and chunks.
+type TableManifest struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+	Chunks  []Chunk  `json:"chunks"`
+}
+
+// Chunk is one compressed file of rows.
+type Chunk struct {
+	File   string `json:"file"`
+	Rows   int64  `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// Exporter writes snapshots.
+type Exporter struct {
+	DB        *sql.DB
+	ChunkRows int
+}
+
+// Export writes a snapshot into dir, which must not exist or be empty. All
+// tables are read in one read-only REPEATABLE READ transaction, so they are
+// mutually consistent without locking out PutDecision writes.
+func (e *Exporter) Export(ctx context.Context, dir string) (*Manifest, error) {
+	if err := ensureEmptyDir(dir); err != nil {
+		return nil, err
+	}
+	chunkRows := e.ChunkRows
+	if chunkRows <= 0 {
+		chunkRows = DefaultChunkRows
+	}
+
+	tx, err := e.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	m := &Manifest{CreatedAt: time.Now().UTC()}
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&m.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+	for _, t := range Tables {
+		tm, err := exportTable(ctx, tx, dir, t, chunkRows)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", t.Name, err)
+		}
+		m.Tables = append(m.Tables, *tm)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// The manifest goes last: a directory without one is an incomplete snapshot.
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), b, 0o644); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func exportTable(ctx context.Context, tx *sql.Tx, dir string, t Table, chunkRows int) (*TableManifest, error) {
+	// Table and column names come from Tables, never from input.
+	rows, err := tx.QueryContext(ctx, `SELECT * FROM `+t.Name+` ORDER BY `+t.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	tm := &TableManifest{Name: t.Name, Columns: cols}
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	var w *chunkWriter
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		if w == nil {
+			name := fmt.Sprintf("%s-%05d.jsonl.gz", t.Name, len(tm.Chunks)+1)
+			if w, err = newChunkWriter(filepath.Join(dir, name)); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.write(encodeRow(values)); err != nil {
+			w.close()
+			return nil, err
+		}
+		if w.rows == int64(chunkRows) {
+			if err := finishChunk(tm, w); err != nil {
+				return nil, err
+			}
+			w = nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if w != nil {
+			w.close()
+		}
+		return nil, err
+	}
+	if w != nil {
+		if err := finishChunk(tm, w); err != nil {
+			return nil, err
+		}
+	}
+	return tm, nil
+}
+
+func finishChunk(tm *TableManifest, w *chunkWriter) error {
+	sum, err := w.close()
+	if err != nil {
+		return err
+	}
+	tm.Chunks = append(tm.Chunks, Chunk{File: filepath.Base(w.path), Rows: w.rows, SHA256: sum})
+	tm.Rows += w.rows
+	return nil
+}
+
+// encodeRow converts driver values into JSON-friendly ones.
+func encodeRow(values []any) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case []byte:
+			out[i] = string(v)
+		case time.Time:
+			out[i] = v.Format(timeLayout)
+		default:
+			out[i] = v
+		}
+	}
+	return out
+}
+
+// chunkWriter gzips JSON lines into a file while hashing the compressed bytes.
+type chunkWriter struct {
+	path string
+	f    *os.File
+	sum  hash.Hash
+	gz   *gzip.Writer
+	enc  *json.Encoder
+	rows int64
+}
+
+func newChunkWriter(path string) (*chunkWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(f, sum))
+	return &chunkWriter{path: path, f: f, sum: sum, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+func (w *chunkWriter) write(row []any) error {
+	w.rows++
+	return w.enc.Encode(row)
+}
+
+func (w *chunkWriter) close() (string, error) {
+	gzErr := w.gz.Close()
+	if err := w.f.Close(); err != nil {
+		return "", err
+	}
+	if gzErr != nil {
+		return "", gzErr
+	}
+	return hex.EncodeToString(w.sum.Sum(nil)), nil
+}
+
+func ensureEmptyDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("snapshot directory %s is not empty", dir)
+	}
+	return nil
+}
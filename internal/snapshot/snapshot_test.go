@@ -0,0 +1,209 @@
+package snapshot_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/KEdore/explore/internal/snapshot"
+)
+
+var decisionColumns = []string{"id", "actor_user_id", "recipient_user_id", "liked_recipient", "created_at", "updated_at"}
+
+// expectTables expects one query per snapshot table; decisions gets rows and
+// the rest are empty.
+func expectTables(mock sqlmock.Sqlmock, decisions *sqlmock.Rows) {
+	for _, t := range snapshot.Tables {
+		q := mock.ExpectQuery(`SELECT \* FROM ` + t.Name)
+		if t.Name == "decisions" {
+			q.WillReturnRows(decisions)
+		} else {
+			q.WillReturnRows(sqlmock.NewRows([]string{"actor_user_id"}))
+		}
+	}
+}
+
+func exportSnapshot(t *testing.T, dir string) *snapshot.Manifest {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(5))
+	expectTables(mock, sqlmock.NewRows(decisionColumns).
+		AddRow(1, []byte("a"), []byte("b"), 1, ts, ts).
+		AddRow(2, []byte("a"), []byte("c"), 0, ts, ts).
+		AddRow(3, []byte("b"), []byte("a"), 1, ts, ts))
+	mock.ExpectCommit()
+
+	m, err := (&snapshot.Exporter{DB: db, ChunkRows: 2}).Export(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+	return m
+}
+
+func TestExport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snap")
+	m := exportSnapshot(t, dir)
+
+	if m.SchemaVersion != 5 {
+		t.Errorf("expected schema version 5, got %d", m.SchemaVersion)
+	}
+	decisions := m.Tables[0]
+	if decisions.Rows != 3 || len(decisions.Chunks) != 2 {
+		t.Fatalf("expected 3 rows in 2 chunks, got %d rows in %d chunks", decisions.Rows, len(decisions.Chunks))
+	}
+	if _, err := snapshot.Verify(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Corrupting a chunk must fail verification.
+	path := filepath.Join(dir, decisions.Chunks[0].File)
+	b, _ := os.ReadFile(path)
+	b[len(b)-1] ^= 0xff
+	os.WriteFile(path, b, 0o644)
+	if _, err := snapshot.Verify(dir); err == nil {
+		t.Error("expected a checksum error")
+	}
+}
+
+func TestExport_NonEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "stale"), nil, 0o644)
+	if _, err := (&snapshot.Exporter{DB: &sql.DB{}}).Export(context.Background(), dir); err == nil ||
+		!strings.Contains(err.Error(), "not empty") {
+		t.Errorf("expected a non-empty directory error, got %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snap")
+	exportSnapshot(t, dir)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(5))
+	for _, tb := range snapshot.Tables {
+		cols := []string{"actor_user_id"}
+		if tb.Name == "decisions" {
+			cols = decisionColumns
+		}
+		mock.ExpectQuery(`SELECT \* FROM ` + tb.Name + ` LIMIT 1`).WillReturnRows(sqlmock.NewRows(cols))
+	}
+	// Chunks of two and one rows, each in its own transaction and loaded in
+	// batches of at most two.
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO decisions \(id, actor_user_id, recipient_user_id, liked_recipient, created_at, updated_at\) VALUES \(\?, \?, \?, \?, \?, \?\), \(\?, \?, \?, \?, \?, \?\)$`).
+		WithArgs("1", "a", "b", "1", "2024-05-01 12:00:00", "2024-05-01 12:00:00",
+			"2", "a", "c", "0", "2024-05-01 12:00:00", "2024-05-01 12:00:00").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO decisions .* VALUES \(\?, \?, \?, \?, \?, \?\)$`).
+		WithArgs("3", "b", "a", "1", "2024-05-01 12:00:00", "2024-05-01 12:00:00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if _, err := (&snapshot.Restorer{DB: db, BatchRows: 2}).Restore(context.Background(), dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRestore_Resume(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snap")
+	exportSnapshot(t, dir)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(5))
+	for _, tb := range snapshot.Tables {
+		cols := []string{"actor_user_id"}
+		count := 0
+		rows := sqlmock.NewRows(cols)
+		if tb.Name == "decisions" {
+			// The first chunk committed before the previous attempt failed.
+			rows = sqlmock.NewRows(decisionColumns).AddRow(1, "a", "b", 1, nil, nil)
+			count = 2
+		}
+		mock.ExpectQuery(`SELECT \* FROM ` + tb.Name + ` LIMIT 1`).WillReturnRows(rows)
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM ` + tb.Name).
+			WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(count))
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO decisions .* VALUES \(\?, \?, \?, \?, \?, \?\)$`).
+		WithArgs("3", "b", "a", "1", "2024-05-01 12:00:00", "2024-05-01 12:00:00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if _, err := (&snapshot.Restorer{DB: db, BatchRows: 2, Resume: true}).Restore(context.Background(), dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRestore_ResumeMidChunk(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snap")
+	exportSnapshot(t, dir)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`schema_migrations`).WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(5))
+	mock.ExpectQuery(`SELECT \* FROM decisions LIMIT 1`).WillReturnRows(sqlmock.NewRows(decisionColumns))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM decisions`).WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+
+	if _, err := (&snapshot.Restorer{DB: db, Resume: true}).Restore(context.Background(), dir); err == nil ||
+		!strings.Contains(err.Error(), "chunk boundary") {
+		t.Errorf("expected a chunk boundary error, got %v", err)
+	}
+}
+
+func TestRestore_SchemaMismatch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snap")
+	exportSnapshot(t, dir)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(`schema_migrations`).WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow(4))
+
+	if _, err := (&snapshot.Restorer{DB: db}).Restore(context.Background(), dir); err == nil ||
+		!strings.Contains(err.Error(), "schema version") {
+		t.Errorf("expected a schema version error, got %v", err)
+	}
+}
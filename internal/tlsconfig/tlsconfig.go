@@ -0,0 +1,166 @@
+// Package tlsconfig builds server TLS configurations whose certificates are
+// reloaded from disk when the files change.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Client authentication modes.
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request" // verify a client certificate if one is sent
+	ClientAuthRequire = "require" // mutual TLS
+)
+
+// Options names the PEM files and the client authentication mode.
+type Options struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	ClientAuth   string
+}
+
+// Reloader serves the most recently loaded certificate and client CA pool.
+type Reloader struct {
+	opts       Options
+	clientAuth tls.ClientAuthType
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewReloader loads the files once and fails if they are unusable.
+func NewReloader(opts Options) (*Reloader, error) {
+	r := &Reloader{opts: opts}
+	switch opts.ClientAuth {
+	case "", ClientAuthNone:
+		r.clientAuth = tls.NoClientCert
+	case ClientAuthRequest:
+		r.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		r.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown client auth mode %q", opts.ClientAuth)
+	}
+	if r.clientAuth != tls.NoClientCert && opts.ClientCAFile == "" {
+		return nil, fmt.Errorf("client auth %q needs a client CA file", opts.ClientAuth)
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
+
+// Reload reads the certificate, key and client CA files. On failure the
+// previously loaded material stays in use.
+func (r *Reloader) Reload() error {
+	modTimes := make(map[string]time.Time)
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load server certificate: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", r.opts.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// changed reports whether any file's modification time differs from the last load.
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			// Mid-rotation; try again on the next tick.
+			return false
+		}
+		if !info.ModTime().Equal(r.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch polls the files every interval and reloads them when they change.
+// Polling also catches Kubernetes secret updates, which swap symlinks.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !r.changed() {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			slog.Error("TLS reload failed, keeping previous certificate", "event", "tls.reload_failed", "error", err)
+			continue
+		}
+		slog.Info("TLS certificates reloaded", "event", "tls.reloaded")
+	}
+}
+
+// ServerConfig returns a TLS configuration that always uses the latest material.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    r.clientCA,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}
+}
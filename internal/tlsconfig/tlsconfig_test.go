@@ -0,0 +1,194 @@
+package tlsconfig_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KEdore/explore/internal/tlsconfig"
+)
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue creates a certificate signed by parent, or a self-signed CA when parent is nil.
+func issue(t *testing.T, cn string, parent *keyPair) *keyPair {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &keyPair{cert: cert, key: key}
+}
+
+// write stores kp as PEM files and bumps their modification time to at.
+func write(t *testing.T, kp *keyPair, certFile, keyFile string, at time.Time) {
+	t.Helper()
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: kp.cert.Raw})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile != "" {
+		der, _ := x509.MarshalECPrivateKey(kp.key)
+		if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(keyFile, at, at)
+	}
+	os.Chtimes(certFile, at, at)
+}
+
+// serve accepts TLS connections and completes their handshakes.
+func serve(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+func handshake(addr string, cfg *tls.Config) (string, error) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+}
+
+// TestReloader_HotReload verifies that a rotated certificate is served without a restart.
+func TestReloader_HotReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := issue(t, "ca", nil)
+	write(t, issue(t, "first", ca), certFile, keyFile, time.Now().Add(-time.Minute))
+
+	r, err := tlsconfig.NewReloader(tlsconfig.Options{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	addr := serve(t, r.ServerConfig())
+	client := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+
+	if cn, err := handshake(addr, client); err != nil || cn != "first" {
+		t.Fatalf("expected first certificate, got %q, %v", cn, err)
+	}
+
+	write(t, issue(t, "second", ca), certFile, keyFile, time.Now())
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		cn, err := handshake(addr, client)
+		if err == nil && cn == "second" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("certificate was not reloaded, still serving %q (%v)", cn, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestReloader_MutualTLS verifies that clients must present a certificate from the client CA.
+func TestReloader_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	ca := issue(t, "ca", nil)
+	write(t, issue(t, "server", ca), certFile, keyFile, time.Now())
+	write(t, ca, caFile, "", time.Now())
+
+	r, err := tlsconfig.NewReloader(tlsconfig.Options{
+		CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: tlsconfig.ClientAuthRequire,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addr := serve(t, r.ServerConfig())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := issue(t, "matcher", ca)
+	withCert := &tls.Config{
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{{Certificate: [][]byte{client.cert.Raw}, PrivateKey: client.key}},
+	}
+	if _, err := handshake(addr, withCert); err != nil {
+		t.Errorf("expected a client with a certificate to connect, got %v", err)
+	}
+
+	// With TLS 1.3 the server rejects the missing certificate after the client
+	// finishes, so the failure shows up on the first read.
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, ServerName: "localhost"})
+	if err == nil {
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = conn.Read(make([]byte, 1))
+	}
+	if err == nil {
+		t.Error("expected a client without a certificate to be rejected")
+	}
+}
+
+// TestNewReloader_Invalid verifies configuration errors.
+func TestNewReloader_Invalid(t *testing.T) {
+	if _, err := tlsconfig.NewReloader(tlsconfig.Options{CertFile: "missing.crt", KeyFile: "missing.key"}); err == nil {
+		t.Error("expected an error for missing files")
+	}
+	if _, err := tlsconfig.NewReloader(tlsconfig.Options{ClientAuth: tlsconfig.ClientAuthRequire}); err == nil {
+		t.Error("expected an error for mTLS without a client CA")
+	}
+}
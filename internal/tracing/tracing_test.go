@@ -0,0 +1,67 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/tracing"
+)
+
+// TestUnaryServerInterceptor verifies that the incoming trace context is continued.
+func TestUnaryServerInterceptor(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Options{Exporter: tracing.ExporterNone}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(exporter, "explore-test", 1)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"traceparent", "00-"+traceID+"-00f067aa0ba902b7-01",
+	))
+	info := &grpc.UnaryServerInfo{FullMethod: "/explore.ExploreService/CountLikedYou"}
+
+	var handlerSpan trace.SpanContext
+	_, err := tracing.UnaryServerInterceptor(tp)(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return nil, status.Error(codes.Unavailable, "db down")
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected handler error to pass through, got %v", err)
+	}
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if got := span.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("expected trace ID %s, got %s", traceID, got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("expected remote parent span, got %s", got)
+	}
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("expected a server span, got %v", span.SpanKind)
+	}
+	if handlerSpan.SpanID() != span.SpanContext.SpanID() {
+		t.Errorf("expected the handler context to carry the server span")
+	}
+}
+
+// TestSetup_UnknownExporter verifies that typos in the exporter name are rejected.
+func TestSetup_UnknownExporter(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Options{Exporter: "jaeger"}); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
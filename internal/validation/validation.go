@@ -0,0 +1,151 @@
+// Package validation checks request messages against declarative rules and
+// reports every violated field at once as google.rpc.BadRequest details.
+package validation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+
+	"github.com/KEdore/explore/internal/apperr"
+)
+
+// Violation describes one invalid field.
+type Violation struct {
+	Field       string
+	Description string
+}
+
+// StringRule checks a single string value and returns a description of the
+// problem, or "" when the value is valid.
+type StringRule func(value string) string
+
+// Required rejects empty values.
+func Required() StringRule {
+	return func(v string) string {
+		if v == "" {
+			return "must not be empty"
+		}
+		return ""
+	}
+}
+
+// MaxLen rejects values longer than n characters.
+func MaxLen(n int) StringRule {
+	return func(v string) string {
+		if utf8.RuneCountInString(v) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+// Matches rejects non-empty values that do not match re.
+func Matches(re *regexp.Regexp) StringRule {
+	return func(v string) string {
+		if v != "" && !re.MatchString(v) {
+			return fmt.Sprintf("must match %s", re.String())
+		}
+		return ""
+	}
+}
+
+// Rule checks a whole message of type T.
+type Rule[T any] func(msg T) []Violation
+
+// Field applies string rules to the field returned by get. Only the first
+// failing rule is reported so each field yields at most one violation.
+func Field[T any](name string, get func(T) string, rules ...StringRule) Rule[T] {
+	return func(msg T) []Violation {
+		v := get(msg)
+		for _, rule := range rules {
+			if desc := rule(v); desc != "" {
+				return []Violation{{Field: name, Description: desc}}
+			}
+		}
+		return nil
+	}
+}
+
+// NotEqual rejects messages where two non-empty fields hold the same value.
+// The violation is reported against field.
+func NotEqual[T any](field, other string, get, getOther func(T) string) Rule[T] {
+	return func(msg T) []Violation {
+		if a := get(msg); a != "" && a == getOther(msg) {
+			return []Violation{{Field: field, Description: "must differ from " + other}}
+		}
+		return nil
+	}
+}
+
+// Validator holds the rules registered for each message type.
+type Validator struct {
+	rules map[reflect.Type]func(any) []Violation
+}
+
+// New returns an empty Validator. Messages without rules always pass.
+func New() *Validator {
+	return &Validator{rules: make(map[reflect.Type]func(any) []Violation)}
+}
+
+// Register adds rules for messages of type T.
+func Register[T any](v *Validator, rules ...Rule[T]) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	prev := v.rules[t]
+	v.rules[t] = func(msg any) []Violation {
+		var out []Violation
+		if prev != nil {
+			out = prev(msg)
+		}
+		typed := msg.(T)
+		for _, rule := range rules {
+			out = append(out, rule(typed)...)
+		}
+		return out
+	}
+}
+
+// Violations returns every rule violated by msg.
+func (v *Validator) Violations(msg any) []Violation {
+	check, ok := v.rules[reflect.TypeOf(msg)]
+	if !ok {
+		return nil
+	}
+	return check(msg)
+}
+
+// Validate returns an InvalidArgument error with BadRequest details when msg
+// violates any rule.
+func (v *Validator) Validate(msg any) error {
+	violations := v.Violations(msg)
+	if len(violations) == 0 {
+		return nil
+	}
+	br := &errdetails.BadRequest{}
+	for _, vi := range violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       vi.Field,
+			Description: vi.Description,
+		})
+	}
+	msgText := fmt.Sprintf("invalid %s: %s", violations[0].Field, violations[0].Description)
+	if len(violations) > 1 {
+		msgText = fmt.Sprintf("%s (and %d more)", msgText, len(violations)-1)
+	}
+	return apperr.InvalidArgument(apperr.ReasonInvalidArgument, msgText).WithDetails(br)
+}
+
+// UnaryServerInterceptor rejects invalid requests before they reach handlers.
+func (v *Validator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := v.Validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,56 @@
+package validation_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/KEdore/explore/internal/validation"
+)
+
+type greeting struct{ from, to string }
+
+func (g *greeting) From() string { return g.from }
+func (g *greeting) To() string   { return g.to }
+
+// TestValidator verifies rule registration, ordering and the interceptor.
+func TestValidator(t *testing.T) {
+	v := validation.New()
+	validation.Register(v,
+		validation.Field("from", (*greeting).From, validation.Required(), validation.MaxLen(3)),
+		validation.Field("to", (*greeting).To, validation.Required()),
+		validation.NotEqual("to", "from", (*greeting).To, (*greeting).From),
+	)
+
+	got := v.Violations(&greeting{from: "", to: ""})
+	if len(got) != 2 || got[0].Field != "from" || got[1].Field != "to" {
+		t.Errorf("expected from and to violations in order, got %v", got)
+	}
+	got = v.Violations(&greeting{from: "toolong", to: "x"})
+	if len(got) != 1 || got[0].Description != "must be at most 3 characters" {
+		t.Errorf("expected a single length violation, got %v", got)
+	}
+	got = v.Violations(&greeting{from: "bob", to: "bob"})
+	if len(got) != 1 || got[0].Field != "to" {
+		t.Errorf("expected a not-equal violation, got %v", got)
+	}
+	if got := v.Violations("unregistered"); got != nil {
+		t.Errorf("expected unregistered types to pass, got %v", got)
+	}
+
+	called := false
+	_, err := v.UnaryServerInterceptor()(context.Background(), &greeting{}, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req any) (any, error) {
+			called = true
+			return nil, nil
+		})
+	if called {
+		t.Error("expected the handler not to run for an invalid request")
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
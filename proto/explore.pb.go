@@ -22,11 +22,15 @@ const (
 )
 
 type ListLikedYouRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	RecipientUserId string                 `protobuf:"bytes,1,opt,name=recipient_user_id,json=recipientUserId,proto3" json:"recipient_user_id,omitempty"`
-	PaginationToken *string                `protobuf:"bytes,2,opt,name=pagination_token,json=paginationToken,proto3,oneof" json:"pagination_token,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	RecipientUserId    string                 `protobuf:"bytes,1,opt,name=recipient_user_id,json=recipientUserId,proto3" json:"recipient_user_id,omitempty"`
+	PaginationToken    *string                `protobuf:"bytes,2,opt,name=pagination_token,json=paginationToken,proto3,oneof" json:"pagination_token,omitempty"`             // Filters must be repeated unchanged on every page
+	SinceUnixTimestamp *uint64                `protobuf:"varint,3,opt,name=since_unix_timestamp,json=sinceUnixTimestamp,proto3,oneof" json:"since_unix_timestamp,omitempty"` // Only likes made at or after this time
+	UntilUnixTimestamp *uint64                `protobuf:"varint,4,opt,name=until_unix_timestamp,json=untilUnixTimestamp,proto3,oneof" json:"until_unix_timestamp,omitempty"` // Only likes made before this time
+	UnseenOnly         *bool                  `protobuf:"varint,5,opt,name=unseen_only,json=unseenOnly,proto3,oneof" json:"unseen_only,omitempty"`                           // Only likes the recipient has not marked seen
+	UndecidedOnly      *bool                  `protobuf:"varint,6,opt,name=undecided_only,json=undecidedOnly,proto3,oneof" json:"undecided_only,omitempty"`                  // Only likers the recipient has neither liked nor passed
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *ListLikedYouRequest) Reset() {
@@ -73,6 +77,34 @@ func (x *ListLikedYouRequest) GetPaginationToken() string {
 	return ""
 }
 
+func (x *ListLikedYouRequest) GetSinceUnixTimestamp() uint64 {
+	if x != nil && x.SinceUnixTimestamp != nil {
+		return *x.SinceUnixTimestamp
+	}
+	return 0
+}
+
+func (x *ListLikedYouRequest) GetUntilUnixTimestamp() uint64 {
+	if x != nil && x.UntilUnixTimestamp != nil {
+		return *x.UntilUnixTimestamp
+	}
+	return 0
+}
+
+func (x *ListLikedYouRequest) GetUnseenOnly() bool {
+	if x != nil && x.UnseenOnly != nil {
+		return *x.UnseenOnly
+	}
+	return false
+}
+
+func (x *ListLikedYouRequest) GetUndecidedOnly() bool {
+	if x != nil && x.UndecidedOnly != nil {
+		return *x.UndecidedOnly
+	}
+	return false
+}
+
 type ListLikedYouResponse struct {
 	state               protoimpl.MessageState        `protogen:"open.v1"`
 	Likers              []*ListLikedYouResponse_Liker `protobuf:"bytes,1,rep,name=likers,proto3" json:"likers,omitempty"`
@@ -128,6 +160,7 @@ func (x *ListLikedYouResponse) GetNextPaginationToken() string {
 type CountLikedYouRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	RecipientUserId string                 `protobuf:"bytes,1,opt,name=recipient_user_id,json=recipientUserId,proto3" json:"recipient_user_id,omitempty"`
+	UndecidedOnly   *bool                  `protobuf:"varint,2,opt,name=undecided_only,json=undecidedOnly,proto3,oneof" json:"undecided_only,omitempty"` // Only count likers the recipient has neither liked nor passed
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -169,6 +202,13 @@ func (x *CountLikedYouRequest) GetRecipientUserId() string {
 	return ""
 }
 
+func (x *CountLikedYouRequest) GetUndecidedOnly() bool {
+	if x != nil && x.UndecidedOnly != nil {
+		return *x.UndecidedOnly
+	}
+	return false
+}
+
 type CountLikedYouResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Count         uint64                 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
@@ -218,6 +258,7 @@ type PutDecisionRequest struct {
 	ActorUserId     string                 `protobuf:"bytes,1,opt,name=actor_user_id,json=actorUserId,proto3" json:"actor_user_id,omitempty"`
 	RecipientUserId string                 `protobuf:"bytes,2,opt,name=recipient_user_id,json=recipientUserId,proto3" json:"recipient_user_id,omitempty"`
 	LikedRecipient  bool                   `protobuf:"varint,3,opt,name=liked_recipient,json=likedRecipient,proto3" json:"liked_recipient,omitempty"`
+	IdempotencyKey  *string                `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3,oneof" json:"idempotency_key,omitempty"` // Replays with the same key return the original response; may also be sent as idempotency-key metadata
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -273,6 +314,13 @@ func (x *PutDecisionRequest) GetLikedRecipient() bool {
 	return false
 }
 
+func (x *PutDecisionRequest) GetIdempotencyKey() string {
+	if x != nil && x.IdempotencyKey != nil {
+		return *x.IdempotencyKey
+	}
+	return ""
+}
+
 type PutDecisionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MutualLikes   bool                   `protobuf:"varint,1,opt,name=mutual_likes,json=mutualLikes,proto3" json:"mutual_likes,omitempty"` // True if both users like each other
@@ -317,17 +365,511 @@ func (x *PutDecisionResponse) GetMutualLikes() bool {
 	return false
 }
 
+type MarkLikesSeenRequest struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	RecipientUserId        string                 `protobuf:"bytes,1,opt,name=recipient_user_id,json=recipientUserId,proto3" json:"recipient_user_id,omitempty"`
+	SeenUntilUnixTimestamp *uint64                `protobuf:"varint,2,opt,name=seen_until_unix_timestamp,json=seenUntilUnixTimestamp,proto3,oneof" json:"seen_until_unix_timestamp,omitempty"` // Likes made at or before this time are seen; defaults to now unless actor_user_ids is set
+	ActorUserIds           []string               `protobuf:"bytes,3,rep,name=actor_user_ids,json=actorUserIds,proto3" json:"actor_user_ids,omitempty"`                                        // Likes from these actors are seen
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *MarkLikesSeenRequest) Reset() {
+	*x = MarkLikesSeenRequest{}
+	mi := &file_explore_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkLikesSeenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkLikesSeenRequest) ProtoMessage() {}
+
+func (x *MarkLikesSeenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkLikesSeenRequest.ProtoReflect.Descriptor instead.
+func (*MarkLikesSeenRequest) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MarkLikesSeenRequest) GetRecipientUserId() string {
+	if x != nil {
+		return x.RecipientUserId
+	}
+	return ""
+}
+
+func (x *MarkLikesSeenRequest) GetSeenUntilUnixTimestamp() uint64 {
+	if x != nil && x.SeenUntilUnixTimestamp != nil {
+		return *x.SeenUntilUnixTimestamp
+	}
+	return 0
+}
+
+func (x *MarkLikesSeenRequest) GetActorUserIds() []string {
+	if x != nil {
+		return x.ActorUserIds
+	}
+	return nil
+}
+
+type MarkLikesSeenResponse struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	SeenUntilUnixTimestamp uint64                 `protobuf:"varint,1,opt,name=seen_until_unix_timestamp,json=seenUntilUnixTimestamp,proto3" json:"seen_until_unix_timestamp,omitempty"` // The recipient's watermark after the update; 0 if none
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *MarkLikesSeenResponse) Reset() {
+	*x = MarkLikesSeenResponse{}
+	mi := &file_explore_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkLikesSeenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkLikesSeenResponse) ProtoMessage() {}
+
+func (x *MarkLikesSeenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkLikesSeenResponse.ProtoReflect.Descriptor instead.
+func (*MarkLikesSeenResponse) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MarkLikesSeenResponse) GetSeenUntilUnixTimestamp() uint64 {
+	if x != nil {
+		return x.SeenUntilUnixTimestamp
+	}
+	return 0
+}
+
+type AbuseFlag struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ActorUserId          string                 `protobuf:"bytes,1,opt,name=actor_user_id,json=actorUserId,proto3" json:"actor_user_id,omitempty"`
+	Score                float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Reasons              []string               `protobuf:"bytes,3,rep,name=reasons,proto3" json:"reasons,omitempty"` // Signals that tripped, e.g. like_velocity
+	FlaggedUnixTimestamp uint64                 `protobuf:"varint,4,opt,name=flagged_unix_timestamp,json=flaggedUnixTimestamp,proto3" json:"flagged_unix_timestamp,omitempty"`
+	ClearedUnixTimestamp *uint64                `protobuf:"varint,5,opt,name=cleared_unix_timestamp,json=clearedUnixTimestamp,proto3,oneof" json:"cleared_unix_timestamp,omitempty"`
+	ClearedBy            *string                `protobuf:"bytes,6,opt,name=cleared_by,json=clearedBy,proto3,oneof" json:"cleared_by,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AbuseFlag) Reset() {
+	*x = AbuseFlag{}
+	mi := &file_explore_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AbuseFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AbuseFlag) ProtoMessage() {}
+
+func (x *AbuseFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AbuseFlag.ProtoReflect.Descriptor instead.
+func (*AbuseFlag) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AbuseFlag) GetActorUserId() string {
+	if x != nil {
+		return x.ActorUserId
+	}
+	return ""
+}
+
+func (x *AbuseFlag) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *AbuseFlag) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *AbuseFlag) GetFlaggedUnixTimestamp() uint64 {
+	if x != nil {
+		return x.FlaggedUnixTimestamp
+	}
+	return 0
+}
+
+func (x *AbuseFlag) GetClearedUnixTimestamp() uint64 {
+	if x != nil && x.ClearedUnixTimestamp != nil {
+		return *x.ClearedUnixTimestamp
+	}
+	return 0
+}
+
+func (x *AbuseFlag) GetClearedBy() string {
+	if x != nil && x.ClearedBy != nil {
+		return *x.ClearedBy
+	}
+	return ""
+}
+
+type ListAbuseFlagsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PaginationToken *string                `protobuf:"bytes,1,opt,name=pagination_token,json=paginationToken,proto3,oneof" json:"pagination_token,omitempty"`
+	IncludeCleared  bool                   `protobuf:"varint,2,opt,name=include_cleared,json=includeCleared,proto3" json:"include_cleared,omitempty"` // Also return flags that were cleared
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListAbuseFlagsRequest) Reset() {
+	*x = ListAbuseFlagsRequest{}
+	mi := &file_explore_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAbuseFlagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAbuseFlagsRequest) ProtoMessage() {}
+
+func (x *ListAbuseFlagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAbuseFlagsRequest.ProtoReflect.Descriptor instead.
+func (*ListAbuseFlagsRequest) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListAbuseFlagsRequest) GetPaginationToken() string {
+	if x != nil && x.PaginationToken != nil {
+		return *x.PaginationToken
+	}
+	return ""
+}
+
+func (x *ListAbuseFlagsRequest) GetIncludeCleared() bool {
+	if x != nil {
+		return x.IncludeCleared
+	}
+	return false
+}
+
+type ListAbuseFlagsResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Flags               []*AbuseFlag           `protobuf:"bytes,1,rep,name=flags,proto3" json:"flags,omitempty"`
+	NextPaginationToken *string                `protobuf:"bytes,2,opt,name=next_pagination_token,json=nextPaginationToken,proto3,oneof" json:"next_pagination_token,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ListAbuseFlagsResponse) Reset() {
+	*x = ListAbuseFlagsResponse{}
+	mi := &file_explore_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAbuseFlagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAbuseFlagsResponse) ProtoMessage() {}
+
+func (x *ListAbuseFlagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAbuseFlagsResponse.ProtoReflect.Descriptor instead.
+func (*ListAbuseFlagsResponse) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListAbuseFlagsResponse) GetFlags() []*AbuseFlag {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+func (x *ListAbuseFlagsResponse) GetNextPaginationToken() string {
+	if x != nil && x.NextPaginationToken != nil {
+		return *x.NextPaginationToken
+	}
+	return ""
+}
+
+type ClearAbuseFlagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActorUserId   string                 `protobuf:"bytes,1,opt,name=actor_user_id,json=actorUserId,proto3" json:"actor_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearAbuseFlagRequest) Reset() {
+	*x = ClearAbuseFlagRequest{}
+	mi := &file_explore_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearAbuseFlagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearAbuseFlagRequest) ProtoMessage() {}
+
+func (x *ClearAbuseFlagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearAbuseFlagRequest.ProtoReflect.Descriptor instead.
+func (*ClearAbuseFlagRequest) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ClearAbuseFlagRequest) GetActorUserId() string {
+	if x != nil {
+		return x.ActorUserId
+	}
+	return ""
+}
+
+type ClearAbuseFlagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cleared       bool                   `protobuf:"varint,1,opt,name=cleared,proto3" json:"cleared,omitempty"` // False if the actor had no active flag
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearAbuseFlagResponse) Reset() {
+	*x = ClearAbuseFlagResponse{}
+	mi := &file_explore_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearAbuseFlagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearAbuseFlagResponse) ProtoMessage() {}
+
+func (x *ClearAbuseFlagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearAbuseFlagResponse.ProtoReflect.Descriptor instead.
+func (*ClearAbuseFlagResponse) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ClearAbuseFlagResponse) GetCleared() bool {
+	if x != nil {
+		return x.Cleared
+	}
+	return false
+}
+
+type EraseUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EraseUserRequest) Reset() {
+	*x = EraseUserRequest{}
+	mi := &file_explore_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserRequest) ProtoMessage() {}
+
+func (x *EraseUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserRequest.ProtoReflect.Descriptor instead.
+func (*EraseUserRequest) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *EraseUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// Counts are totals across every call for the user, so a retried erasure
+// reports what was erased overall.
+type EraseUserResponse struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	DecisionsMadeDeleted     uint64                 `protobuf:"varint,1,opt,name=decisions_made_deleted,json=decisionsMadeDeleted,proto3" json:"decisions_made_deleted,omitempty"`
+	DecisionsReceivedDeleted uint64                 `protobuf:"varint,2,opt,name=decisions_received_deleted,json=decisionsReceivedDeleted,proto3" json:"decisions_received_deleted,omitempty"`
+	DerivedRowsDeleted       uint64                 `protobuf:"varint,3,opt,name=derived_rows_deleted,json=derivedRowsDeleted,proto3" json:"derived_rows_deleted,omitempty"` // Idempotency keys, activity counters and abuse flags
+	CompletedUnixTimestamp   uint64                 `protobuf:"varint,4,opt,name=completed_unix_timestamp,json=completedUnixTimestamp,proto3" json:"completed_unix_timestamp,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *EraseUserResponse) Reset() {
+	*x = EraseUserResponse{}
+	mi := &file_explore_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserResponse) ProtoMessage() {}
+
+func (x *EraseUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_explore_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserResponse.ProtoReflect.Descriptor instead.
+func (*EraseUserResponse) Descriptor() ([]byte, []int) {
+	return file_explore_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *EraseUserResponse) GetDecisionsMadeDeleted() uint64 {
+	if x != nil {
+		return x.DecisionsMadeDeleted
+	}
+	return 0
+}
+
+func (x *EraseUserResponse) GetDecisionsReceivedDeleted() uint64 {
+	if x != nil {
+		return x.DecisionsReceivedDeleted
+	}
+	return 0
+}
+
+func (x *EraseUserResponse) GetDerivedRowsDeleted() uint64 {
+	if x != nil {
+		return x.DerivedRowsDeleted
+	}
+	return 0
+}
+
+func (x *EraseUserResponse) GetCompletedUnixTimestamp() uint64 {
+	if x != nil {
+		return x.CompletedUnixTimestamp
+	}
+	return 0
+}
+
 type ListLikedYouResponse_Liker struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ActorId       string                 `protobuf:"bytes,1,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
-	UnixTimestamp uint64                 `protobuf:"varint,2,opt,name=unix_timestamp,json=unixTimestamp,proto3" json:"unix_timestamp,omitempty"`
+	UnixTimestamp uint64                 `protobuf:"varint,2,opt,name=unix_timestamp,json=unixTimestamp,proto3" json:"unix_timestamp,omitempty"` // When the like was last made
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListLikedYouResponse_Liker) Reset() {
 	*x = ListLikedYouResponse_Liker{}
-	mi := &file_explore_proto_msgTypes[6]
+	mi := &file_explore_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -339,7 +881,7 @@ func (x *ListLikedYouResponse_Liker) String() string {
 func (*ListLikedYouResponse_Liker) ProtoMessage() {}
 
 func (x *ListLikedYouResponse_Liker) ProtoReflect() protoreflect.Message {
-	mi := &file_explore_proto_msgTypes[6]
+	mi := &file_explore_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -373,74 +915,205 @@ var File_explore_proto protoreflect.FileDescriptor
 
 var file_explore_proto_rawDesc = string([]byte{
 	0x0a, 0x0d, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x07, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x22, 0x86, 0x01, 0x0a, 0x13, 0x4c, 0x69, 0x73,
+	0x07, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x22, 0x9b, 0x03, 0x0a, 0x13, 0x4c, 0x69, 0x73,
 	0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x73,
 	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x63,
 	0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2e, 0x0a, 0x10,
 	0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
 	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0f, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x13, 0x0a, 0x11,
+	0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x35, 0x0a, 0x14,
+	0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x48, 0x01, 0x52, 0x12, 0x73, 0x69,
+	0x6e, 0x63, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x88, 0x01, 0x01, 0x12, 0x35, 0x0a, 0x14, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x75, 0x6e, 0x69,
+	0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x48, 0x02, 0x52, 0x12, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x55, 0x6e, 0x69, 0x78, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0b, 0x75, 0x6e,
+	0x73, 0x65, 0x65, 0x6e, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x03, 0x52, 0x0a, 0x75, 0x6e, 0x73, 0x65, 0x65, 0x6e, 0x4f, 0x6e, 0x6c, 0x79, 0x88, 0x01, 0x01,
+	0x12, 0x2a, 0x0a, 0x0e, 0x75, 0x6e, 0x64, 0x65, 0x63, 0x69, 0x64, 0x65, 0x64, 0x5f, 0x6f, 0x6e,
+	0x6c, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x48, 0x04, 0x52, 0x0d, 0x75, 0x6e, 0x64, 0x65,
+	0x63, 0x69, 0x64, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x88, 0x01, 0x01, 0x42, 0x13, 0x0a, 0x11,
 	0x5f, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
-	0x6e, 0x22, 0xf1, 0x01, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59,
-	0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x6c, 0x69,
-	0x6b, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x65, 0x78, 0x70,
-	0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f,
-	0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4c, 0x69, 0x6b, 0x65, 0x72, 0x52,
-	0x06, 0x6c, 0x69, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x37, 0x0a, 0x15, 0x6e, 0x65, 0x78, 0x74, 0x5f,
-	0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x13, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61,
-	0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x88, 0x01, 0x01,
-	0x1a, 0x49, 0x0a, 0x05, 0x4c, 0x69, 0x6b, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x61, 0x63, 0x74,
-	0x6f, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x63, 0x74,
-	0x6f, 0x72, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x75, 0x6e,
-	0x69, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x42, 0x18, 0x0a, 0x16, 0x5f,
+	0x6e, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x78,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x75,
+	0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x75, 0x6e, 0x73, 0x65, 0x65, 0x6e, 0x5f, 0x6f,
+	0x6e, 0x6c, 0x79, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x75, 0x6e, 0x64, 0x65, 0x63, 0x69, 0x64, 0x65,
+	0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x22, 0xf1, 0x01, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x4c,
+	0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x3b, 0x0a, 0x06, 0x6c, 0x69, 0x6b, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x23, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69,
+	0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4c,
+	0x69, 0x6b, 0x65, 0x72, 0x52, 0x06, 0x6c, 0x69, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x37, 0x0a, 0x15,
 	0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
-	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x42, 0x0a, 0x14, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69,
-	0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a,
-	0x11, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69,
-	0x65, 0x6e, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x22, 0x2d, 0x0a, 0x15, 0x43, 0x6f, 0x75,
-	0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x8d, 0x01, 0x0a, 0x12, 0x50, 0x75, 0x74,
-	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x22, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x55, 0x73, 0x65,
-	0x72, 0x49, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74,
-	0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x13, 0x6e,
+	0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x88, 0x01, 0x01, 0x1a, 0x49, 0x0a, 0x05, 0x4c, 0x69, 0x6b, 0x65, 0x72, 0x12, 0x19,
+	0x0a, 0x08, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x6e, 0x69,
+	0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0d, 0x75, 0x6e, 0x69, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x42, 0x18, 0x0a, 0x16, 0x5f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x81, 0x01, 0x0a, 0x14, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74,
+	0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
 	0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12,
-	0x27, 0x0a, 0x0f, 0x6c, 0x69, 0x6b, 0x65, 0x64, 0x5f, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65,
-	0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x6c, 0x69, 0x6b, 0x65, 0x64, 0x52,
-	0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0x38, 0x0a, 0x13, 0x50, 0x75, 0x74, 0x44,
-	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x21, 0x0a, 0x0c, 0x6d, 0x75, 0x74, 0x75, 0x61, 0x6c, 0x5f, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6d, 0x75, 0x74, 0x75, 0x61, 0x6c, 0x4c, 0x69, 0x6b,
-	0x65, 0x73, 0x32, 0xc7, 0x02, 0x0a, 0x0e, 0x45, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x53, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4b, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b,
-	0x65, 0x64, 0x59, 0x6f, 0x75, 0x12, 0x1c, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e,
-	0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69,
-	0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x4e, 0x65, 0x77, 0x4c, 0x69, 0x6b,
-	0x65, 0x64, 0x59, 0x6f, 0x75, 0x12, 0x1c, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e,
-	0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69,
-	0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0d, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64,
-	0x59, 0x6f, 0x75, 0x12, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f,
-	0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f, 0x75,
-	0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x48, 0x0a, 0x0b, 0x50, 0x75, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
-	0x6e, 0x12, 0x1b, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x75, 0x74, 0x44,
-	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c,
-	0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x75, 0x74, 0x44, 0x65, 0x63, 0x69,
-	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x21, 0x5a, 0x1f,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4b, 0x45, 0x64, 0x6f, 0x72,
-	0x65, 0x2f, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2a, 0x0a, 0x0e, 0x75, 0x6e, 0x64, 0x65, 0x63, 0x69, 0x64, 0x65, 0x64, 0x5f, 0x6f, 0x6e, 0x6c,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0d, 0x75, 0x6e, 0x64, 0x65, 0x63,
+	0x69, 0x64, 0x65, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x88, 0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f,
+	0x75, 0x6e, 0x64, 0x65, 0x63, 0x69, 0x64, 0x65, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x22, 0x2d,
+	0x0a, 0x15, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xcf, 0x01,
+	0x0a, 0x12, 0x50, 0x75, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x75, 0x73,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x74,
+	0x6f, 0x72, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x63, 0x69,
+	0x70, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x55, 0x73,
+	0x65, 0x72, 0x49, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x6c, 0x69, 0x6b, 0x65, 0x64, 0x5f, 0x72, 0x65,
+	0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x6c,
+	0x69, 0x6b, 0x65, 0x64, 0x52, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x2c, 0x0a,
+	0x0f, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x88, 0x01, 0x01, 0x42, 0x12, 0x0a, 0x10, 0x5f,
+	0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x22,
+	0x38, 0x0a, 0x13, 0x50, 0x75, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x75, 0x74, 0x75, 0x61, 0x6c,
+	0x5f, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6d, 0x75,
+	0x74, 0x75, 0x61, 0x6c, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x22, 0xc6, 0x01, 0x0a, 0x14, 0x4d, 0x61,
+	0x72, 0x6b, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x53, 0x65, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x5f,
+	0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72,
+	0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x3e,
+	0x0a, 0x19, 0x73, 0x65, 0x65, 0x6e, 0x5f, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x75, 0x6e, 0x69,
+	0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x48, 0x00, 0x52, 0x16, 0x73, 0x65, 0x65, 0x6e, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x55, 0x6e,
+	0x69, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x88, 0x01, 0x01, 0x12, 0x24,
+	0x0a, 0x0e, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x55, 0x73, 0x65,
+	0x72, 0x49, 0x64, 0x73, 0x42, 0x1c, 0x0a, 0x1a, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x5f, 0x75, 0x6e,
+	0x74, 0x69, 0x6c, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x22, 0x52, 0x0a, 0x15, 0x4d, 0x61, 0x72, 0x6b, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x53,
+	0x65, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x19, 0x73,
+	0x65, 0x65, 0x6e, 0x5f, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x16,
+	0x73, 0x65, 0x65, 0x6e, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x55, 0x6e, 0x69, 0x78, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x9e, 0x02, 0x0a, 0x09, 0x41, 0x62, 0x75, 0x73, 0x65,
+	0x46, 0x6c, 0x61, 0x67, 0x12, 0x22, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x75, 0x73,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x74,
+	0x6f, 0x72, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x66, 0x6c, 0x61, 0x67,
+	0x67, 0x65, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x66, 0x6c, 0x61, 0x67, 0x67, 0x65,
+	0x64, 0x55, 0x6e, 0x69, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x39,
+	0x0a, 0x16, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00,
+	0x52, 0x14, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x55, 0x6e, 0x69, 0x78, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a, 0x63, 0x6c, 0x65,
+	0x61, 0x72, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52,
+	0x09, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x42, 0x79, 0x88, 0x01, 0x01, 0x42, 0x19, 0x0a,
+	0x17, 0x5f, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x63, 0x6c, 0x65,
+	0x61, 0x72, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x22, 0x85, 0x01, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74,
+	0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x2e, 0x0a, 0x10, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0f, 0x70,
+	0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x88, 0x01,
+	0x01, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x63, 0x6c, 0x65,
+	0x61, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x6e, 0x63, 0x6c,
+	0x75, 0x64, 0x65, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x70,
+	0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22,
+	0x95, 0x01, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61,
+	0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x66, 0x6c,
+	0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x65, 0x78, 0x70, 0x6c,
+	0x6f, 0x72, 0x65, 0x2e, 0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x05, 0x66,
+	0x6c, 0x61, 0x67, 0x73, 0x12, 0x37, 0x0a, 0x15, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x13, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x18, 0x0a,
+	0x16, 0x5f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x3b, 0x0a, 0x15, 0x43, 0x6c, 0x65, 0x61, 0x72,
+	0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x22, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x55, 0x73,
+	0x65, 0x72, 0x49, 0x64, 0x22, 0x32, 0x0a, 0x16, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x41, 0x62, 0x75,
+	0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x65, 0x64, 0x22, 0x2b, 0x0a, 0x10, 0x45, 0x72, 0x61, 0x73,
+	0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07,
+	0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75,
+	0x73, 0x65, 0x72, 0x49, 0x64, 0x22, 0xf3, 0x01, 0x0a, 0x11, 0x45, 0x72, 0x61, 0x73, 0x65, 0x55,
+	0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x16, 0x64,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x6d, 0x61, 0x64, 0x65, 0x5f, 0x64, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x64, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x4d, 0x61, 0x64, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x12, 0x3c, 0x0a, 0x1a, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x72,
+	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x18, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12,
+	0x30, 0x0a, 0x14, 0x64, 0x65, 0x72, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x72, 0x6f, 0x77, 0x73, 0x5f,
+	0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x64,
+	0x65, 0x72, 0x69, 0x76, 0x65, 0x64, 0x52, 0x6f, 0x77, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x12, 0x38, 0x0a, 0x18, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x5f, 0x75,
+	0x6e, 0x69, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x16, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x55, 0x6e,
+	0x69, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x32, 0xed, 0x03, 0x0a, 0x0e,
+	0x45, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4b,
+	0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x12, 0x1c,
+	0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b,
+	0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x65,
+	0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64,
+	0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0f, 0x4c,
+	0x69, 0x73, 0x74, 0x4e, 0x65, 0x77, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x12, 0x1c,
+	0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b,
+	0x65, 0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x65,
+	0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64,
+	0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0d, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x12, 0x1d, 0x2e, 0x65,
+	0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65,
+	0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x78,
+	0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64,
+	0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0b, 0x50,
+	0x75, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x2e, 0x65, 0x78, 0x70,
+	0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x50, 0x75, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72,
+	0x65, 0x2e, 0x50, 0x75, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0d, 0x4d, 0x61, 0x72, 0x6b, 0x4c, 0x69, 0x6b,
+	0x65, 0x73, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x1d, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65,
+	0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x53, 0x65, 0x65, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e,
+	0x4d, 0x61, 0x72, 0x6b, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x53, 0x65, 0x65, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x13, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x55, 0x6e,
+	0x73, 0x65, 0x65, 0x6e, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x59, 0x6f, 0x75, 0x12, 0x1d, 0x2e, 0x65,
+	0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65,
+	0x64, 0x59, 0x6f, 0x75, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x78,
+	0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x64,
+	0x59, 0x6f, 0x75, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xff, 0x01, 0x0a, 0x13,
+	0x45, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x51, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x62, 0x75, 0x73, 0x65,
+	0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x51, 0x0a, 0x0e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x41,
+	0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x12, 0x1e, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f,
+	0x72, 0x65, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61,
+	0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f,
+	0x72, 0x65, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x41, 0x62, 0x75, 0x73, 0x65, 0x46, 0x6c, 0x61,
+	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x45, 0x72, 0x61,
+	0x73, 0x65, 0x55, 0x73, 0x65, 0x72, 0x12, 0x19, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65,
+	0x2e, 0x45, 0x72, 0x61, 0x73, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1a, 0x2e, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2e, 0x45, 0x72, 0x61, 0x73,
+	0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x21, 0x5a,
+	0x1f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4b, 0x45, 0x64, 0x6f,
+	0x72, 0x65, 0x2f, 0x65, 0x78, 0x70, 0x6c, 0x6f, 0x72, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -455,7 +1128,7 @@ func file_explore_proto_rawDescGZIP() []byte {
 	return file_explore_proto_rawDescData
 }
 
-var file_explore_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_explore_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_explore_proto_goTypes = []any{
 	(*ListLikedYouRequest)(nil),        // 0: explore.ListLikedYouRequest
 	(*ListLikedYouResponse)(nil),       // 1: explore.ListLikedYouResponse
@@ -463,23 +1136,43 @@ var file_explore_proto_goTypes = []any{
 	(*CountLikedYouResponse)(nil),      // 3: explore.CountLikedYouResponse
 	(*PutDecisionRequest)(nil),         // 4: explore.PutDecisionRequest
 	(*PutDecisionResponse)(nil),        // 5: explore.PutDecisionResponse
-	(*ListLikedYouResponse_Liker)(nil), // 6: explore.ListLikedYouResponse.Liker
+	(*MarkLikesSeenRequest)(nil),       // 6: explore.MarkLikesSeenRequest
+	(*MarkLikesSeenResponse)(nil),      // 7: explore.MarkLikesSeenResponse
+	(*AbuseFlag)(nil),                  // 8: explore.AbuseFlag
+	(*ListAbuseFlagsRequest)(nil),      // 9: explore.ListAbuseFlagsRequest
+	(*ListAbuseFlagsResponse)(nil),     // 10: explore.ListAbuseFlagsResponse
+	(*ClearAbuseFlagRequest)(nil),      // 11: explore.ClearAbuseFlagRequest
+	(*ClearAbuseFlagResponse)(nil),     // 12: explore.ClearAbuseFlagResponse
+	(*EraseUserRequest)(nil),           // 13: explore.EraseUserRequest
+	(*EraseUserResponse)(nil),          // 14: explore.EraseUserResponse
+	(*ListLikedYouResponse_Liker)(nil), // 15: explore.ListLikedYouResponse.Liker
 }
 var file_explore_proto_depIdxs = []int32{
-	6, // 0: explore.ListLikedYouResponse.likers:type_name -> explore.ListLikedYouResponse.Liker
-	0, // 1: explore.ExploreService.ListLikedYou:input_type -> explore.ListLikedYouRequest
-	0, // 2: explore.ExploreService.ListNewLikedYou:input_type -> explore.ListLikedYouRequest
-	2, // 3: explore.ExploreService.CountLikedYou:input_type -> explore.CountLikedYouRequest
-	4, // 4: explore.ExploreService.PutDecision:input_type -> explore.PutDecisionRequest
-	1, // 5: explore.ExploreService.ListLikedYou:output_type -> explore.ListLikedYouResponse
-	1, // 6: explore.ExploreService.ListNewLikedYou:output_type -> explore.ListLikedYouResponse
-	3, // 7: explore.ExploreService.CountLikedYou:output_type -> explore.CountLikedYouResponse
-	5, // 8: explore.ExploreService.PutDecision:output_type -> explore.PutDecisionResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	15, // 0: explore.ListLikedYouResponse.likers:type_name -> explore.ListLikedYouResponse.Liker
+	8,  // 1: explore.ListAbuseFlagsResponse.flags:type_name -> explore.AbuseFlag
+	0,  // 2: explore.ExploreService.ListLikedYou:input_type -> explore.ListLikedYouRequest
+	0,  // 3: explore.ExploreService.ListNewLikedYou:input_type -> explore.ListLikedYouRequest
+	2,  // 4: explore.ExploreService.CountLikedYou:input_type -> explore.CountLikedYouRequest
+	4,  // 5: explore.ExploreService.PutDecision:input_type -> explore.PutDecisionRequest
+	6,  // 6: explore.ExploreService.MarkLikesSeen:input_type -> explore.MarkLikesSeenRequest
+	2,  // 7: explore.ExploreService.CountUnseenLikedYou:input_type -> explore.CountLikedYouRequest
+	9,  // 8: explore.ExploreAdminService.ListAbuseFlags:input_type -> explore.ListAbuseFlagsRequest
+	11, // 9: explore.ExploreAdminService.ClearAbuseFlag:input_type -> explore.ClearAbuseFlagRequest
+	13, // 10: explore.ExploreAdminService.EraseUser:input_type -> explore.EraseUserRequest
+	1,  // 11: explore.ExploreService.ListLikedYou:output_type -> explore.ListLikedYouResponse
+	1,  // 12: explore.ExploreService.ListNewLikedYou:output_type -> explore.ListLikedYouResponse
+	3,  // 13: explore.ExploreService.CountLikedYou:output_type -> explore.CountLikedYouResponse
+	5,  // 14: explore.ExploreService.PutDecision:output_type -> explore.PutDecisionResponse
+	7,  // 15: explore.ExploreService.MarkLikesSeen:output_type -> explore.MarkLikesSeenResponse
+	3,  // 16: explore.ExploreService.CountUnseenLikedYou:output_type -> explore.CountLikedYouResponse
+	10, // 17: explore.ExploreAdminService.ListAbuseFlags:output_type -> explore.ListAbuseFlagsResponse
+	12, // 18: explore.ExploreAdminService.ClearAbuseFlag:output_type -> explore.ClearAbuseFlagResponse
+	14, // 19: explore.ExploreAdminService.EraseUser:output_type -> explore.EraseUserResponse
+	11, // [11:20] is the sub-list for method output_type
+	2,  // [2:11] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_explore_proto_init() }
@@ -489,15 +1182,21 @@ func file_explore_proto_init() {
 	}
 	file_explore_proto_msgTypes[0].OneofWrappers = []any{}
 	file_explore_proto_msgTypes[1].OneofWrappers = []any{}
+	file_explore_proto_msgTypes[2].OneofWrappers = []any{}
+	file_explore_proto_msgTypes[4].OneofWrappers = []any{}
+	file_explore_proto_msgTypes[6].OneofWrappers = []any{}
+	file_explore_proto_msgTypes[8].OneofWrappers = []any{}
+	file_explore_proto_msgTypes[9].OneofWrappers = []any{}
+	file_explore_proto_msgTypes[10].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_explore_proto_rawDesc), len(file_explore_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   16,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_explore_proto_goTypes,
 		DependencyIndexes: file_explore_proto_depIdxs,
@@ -19,10 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ExploreService_ListLikedYou_FullMethodName    = "/explore.ExploreService/ListLikedYou"
-	ExploreService_ListNewLikedYou_FullMethodName = "/explore.ExploreService/ListNewLikedYou"
-	ExploreService_CountLikedYou_FullMethodName   = "/explore.ExploreService/CountLikedYou"
-	ExploreService_PutDecision_FullMethodName     = "/explore.ExploreService/PutDecision"
+	ExploreService_ListLikedYou_FullMethodName        = "/explore.ExploreService/ListLikedYou"
+	ExploreService_ListNewLikedYou_FullMethodName     = "/explore.ExploreService/ListNewLikedYou"
+	ExploreService_CountLikedYou_FullMethodName       = "/explore.ExploreService/CountLikedYou"
+	ExploreService_PutDecision_FullMethodName         = "/explore.ExploreService/PutDecision"
+	ExploreService_MarkLikesSeen_FullMethodName       = "/explore.ExploreService/MarkLikesSeen"
+	ExploreService_CountUnseenLikedYou_FullMethodName = "/explore.ExploreService/CountUnseenLikedYou"
 )
 
 // ExploreServiceClient is the client API for ExploreService service.
@@ -33,6 +35,8 @@ type ExploreServiceClient interface {
 	ListNewLikedYou(ctx context.Context, in *ListLikedYouRequest, opts ...grpc.CallOption) (*ListLikedYouResponse, error)
 	CountLikedYou(ctx context.Context, in *CountLikedYouRequest, opts ...grpc.CallOption) (*CountLikedYouResponse, error)
 	PutDecision(ctx context.Context, in *PutDecisionRequest, opts ...grpc.CallOption) (*PutDecisionResponse, error)
+	MarkLikesSeen(ctx context.Context, in *MarkLikesSeenRequest, opts ...grpc.CallOption) (*MarkLikesSeenResponse, error)
+	CountUnseenLikedYou(ctx context.Context, in *CountLikedYouRequest, opts ...grpc.CallOption) (*CountLikedYouResponse, error)
 }
 
 type exploreServiceClient struct {
@@ -83,6 +87,26 @@ func (c *exploreServiceClient) PutDecision(ctx context.Context, in *PutDecisionR
 	return out, nil
 }
 
+func (c *exploreServiceClient) MarkLikesSeen(ctx context.Context, in *MarkLikesSeenRequest, opts ...grpc.CallOption) (*MarkLikesSeenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarkLikesSeenResponse)
+	err := c.cc.Invoke(ctx, ExploreService_MarkLikesSeen_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exploreServiceClient) CountUnseenLikedYou(ctx context.Context, in *CountLikedYouRequest, opts ...grpc.CallOption) (*CountLikedYouResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountLikedYouResponse)
+	err := c.cc.Invoke(ctx, ExploreService_CountUnseenLikedYou_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ExploreServiceServer is the server API for ExploreService service.
 // All implementations must embed UnimplementedExploreServiceServer
 // for forward compatibility.
@@ -91,6 +115,8 @@ type ExploreServiceServer interface {
 	ListNewLikedYou(context.Context, *ListLikedYouRequest) (*ListLikedYouResponse, error)
 	CountLikedYou(context.Context, *CountLikedYouRequest) (*CountLikedYouResponse, error)
 	PutDecision(context.Context, *PutDecisionRequest) (*PutDecisionResponse, error)
+	MarkLikesSeen(context.Context, *MarkLikesSeenRequest) (*MarkLikesSeenResponse, error)
+	CountUnseenLikedYou(context.Context, *CountLikedYouRequest) (*CountLikedYouResponse, error)
 	mustEmbedUnimplementedExploreServiceServer()
 }
 
@@ -113,6 +139,12 @@ func (UnimplementedExploreServiceServer) CountLikedYou(context.Context, *CountLi
 func (UnimplementedExploreServiceServer) PutDecision(context.Context, *PutDecisionRequest) (*PutDecisionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PutDecision not implemented")
 }
+func (UnimplementedExploreServiceServer) MarkLikesSeen(context.Context, *MarkLikesSeenRequest) (*MarkLikesSeenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkLikesSeen not implemented")
+}
+func (UnimplementedExploreServiceServer) CountUnseenLikedYou(context.Context, *CountLikedYouRequest) (*CountLikedYouResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountUnseenLikedYou not implemented")
+}
 func (UnimplementedExploreServiceServer) mustEmbedUnimplementedExploreServiceServer() {}
 func (UnimplementedExploreServiceServer) testEmbeddedByValue()                        {}
 
@@ -206,6 +238,42 @@ func _ExploreService_PutDecision_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ExploreService_MarkLikesSeen_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkLikesSeenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExploreServiceServer).MarkLikesSeen(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExploreService_MarkLikesSeen_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExploreServiceServer).MarkLikesSeen(ctx, req.(*MarkLikesSeenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExploreService_CountUnseenLikedYou_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountLikedYouRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExploreServiceServer).CountUnseenLikedYou(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExploreService_CountUnseenLikedYou_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExploreServiceServer).CountUnseenLikedYou(ctx, req.(*CountLikedYouRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ExploreService_ServiceDesc is the grpc.ServiceDesc for ExploreService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +297,196 @@ var ExploreService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PutDecision",
 			Handler:    _ExploreService_PutDecision_Handler,
 		},
+		{
+			MethodName: "MarkLikesSeen",
+			Handler:    _ExploreService_MarkLikesSeen_Handler,
+		},
+		{
+			MethodName: "CountUnseenLikedYou",
+			Handler:    _ExploreService_CountUnseenLikedYou_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "explore.proto",
+}
+
+const (
+	ExploreAdminService_ListAbuseFlags_FullMethodName = "/explore.ExploreAdminService/ListAbuseFlags"
+	ExploreAdminService_ClearAbuseFlag_FullMethodName = "/explore.ExploreAdminService/ClearAbuseFlag"
+	ExploreAdminService_EraseUser_FullMethodName      = "/explore.ExploreAdminService/EraseUser"
+)
+
+// ExploreAdminServiceClient is the client API for ExploreAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ExploreAdminService is for operators and trusted backends only.
+type ExploreAdminServiceClient interface {
+	ListAbuseFlags(ctx context.Context, in *ListAbuseFlagsRequest, opts ...grpc.CallOption) (*ListAbuseFlagsResponse, error)
+	ClearAbuseFlag(ctx context.Context, in *ClearAbuseFlagRequest, opts ...grpc.CallOption) (*ClearAbuseFlagResponse, error)
+	EraseUser(ctx context.Context, in *EraseUserRequest, opts ...grpc.CallOption) (*EraseUserResponse, error)
+}
+
+type exploreAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExploreAdminServiceClient(cc grpc.ClientConnInterface) ExploreAdminServiceClient {
+	return &exploreAdminServiceClient{cc}
+}
+
+func (c *exploreAdminServiceClient) ListAbuseFlags(ctx context.Context, in *ListAbuseFlagsRequest, opts ...grpc.CallOption) (*ListAbuseFlagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAbuseFlagsResponse)
+	err := c.cc.Invoke(ctx, ExploreAdminService_ListAbuseFlags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exploreAdminServiceClient) ClearAbuseFlag(ctx context.Context, in *ClearAbuseFlagRequest, opts ...grpc.CallOption) (*ClearAbuseFlagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClearAbuseFlagResponse)
+	err := c.cc.Invoke(ctx, ExploreAdminService_ClearAbuseFlag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exploreAdminServiceClient) EraseUser(ctx context.Context, in *EraseUserRequest, opts ...grpc.CallOption) (*EraseUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EraseUserResponse)
+	err := c.cc.Invoke(ctx, ExploreAdminService_EraseUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExploreAdminServiceServer is the server API for ExploreAdminService service.
+// All implementations must embed UnimplementedExploreAdminServiceServer
+// for forward compatibility.
+//
+// ExploreAdminService is for operators and trusted backends only.
+type ExploreAdminServiceServer interface {
+	ListAbuseFlags(context.Context, *ListAbuseFlagsRequest) (*ListAbuseFlagsResponse, error)
+	ClearAbuseFlag(context.Context, *ClearAbuseFlagRequest) (*ClearAbuseFlagResponse, error)
+	EraseUser(context.Context, *EraseUserRequest) (*EraseUserResponse, error)
+	mustEmbedUnimplementedExploreAdminServiceServer()
+}
+
+// UnimplementedExploreAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedExploreAdminServiceServer struct{}
+
+func (UnimplementedExploreAdminServiceServer) ListAbuseFlags(context.Context, *ListAbuseFlagsRequest) (*ListAbuseFlagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAbuseFlags not implemented")
+}
+func (UnimplementedExploreAdminServiceServer) ClearAbuseFlag(context.Context, *ClearAbuseFlagRequest) (*ClearAbuseFlagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearAbuseFlag not implemented")
+}
+func (UnimplementedExploreAdminServiceServer) EraseUser(context.Context, *EraseUserRequest) (*EraseUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EraseUser not implemented")
+}
+func (UnimplementedExploreAdminServiceServer) mustEmbedUnimplementedExploreAdminServiceServer() {}
+func (UnimplementedExploreAdminServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeExploreAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExploreAdminServiceServer will
+// result in compilation errors.
+type UnsafeExploreAdminServiceServer interface {
+	mustEmbedUnimplementedExploreAdminServiceServer()
+}
+
+func RegisterExploreAdminServiceServer(s grpc.ServiceRegistrar, srv ExploreAdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedExploreAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ExploreAdminService_ServiceDesc, srv)
+}
+
+func _ExploreAdminService_ListAbuseFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAbuseFlagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExploreAdminServiceServer).ListAbuseFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExploreAdminService_ListAbuseFlags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExploreAdminServiceServer).ListAbuseFlags(ctx, req.(*ListAbuseFlagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExploreAdminService_ClearAbuseFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearAbuseFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExploreAdminServiceServer).ClearAbuseFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExploreAdminService_ClearAbuseFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExploreAdminServiceServer).ClearAbuseFlag(ctx, req.(*ClearAbuseFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExploreAdminService_EraseUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExploreAdminServiceServer).EraseUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExploreAdminService_EraseUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExploreAdminServiceServer).EraseUser(ctx, req.(*EraseUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ExploreAdminService_ServiceDesc is the grpc.ServiceDesc for ExploreAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExploreAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "explore.ExploreAdminService",
+	HandlerType: (*ExploreAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAbuseFlags",
+			Handler:    _ExploreAdminService_ListAbuseFlags_Handler,
+		},
+		{
+			MethodName: "ClearAbuseFlag",
+			Handler:    _ExploreAdminService_ClearAbuseFlag_Handler,
+		},
+		{
+			MethodName: "EraseUser",
+			Handler:    _ExploreAdminService_EraseUser_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "explore.proto",